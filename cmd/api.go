@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/geelato/cli/cmd/initializer"
+	cmdsync "github.com/geelato/cli/cmd/sync"
+	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/platform"
+	"github.com/geelato/cli/internal/script"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/geelato/cli/pkg/prompt"
 	"github.com/spf13/cobra"
@@ -103,28 +111,346 @@ func createAPI(apiName, apiType string) error {
 	return initializer.CreateAPIFile(filePath, apiName, apiType)
 }
 
+var (
+	apiTestRemote  bool
+	apiTestJUnit   string
+	apiTestRun     string
+	apiTestTimeout time.Duration
+)
+
 func NewApiTestCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "test <api-file>",
+	cmd := &cobra.Command{
+		Use:   "test [path...]",
 		Short: "test(测试API)",
-		Long:  `测试 API 脚本`,
-		Args:  cobra.ExactArgs(1),
+		Long: `使用测试用例文件测试 API 脚本
+
+测试用例写在 API 脚本旁边的 <name>.api.test.json（或 <name>.api.test.js）中：
+
+  {
+    "mocks": {"entities": {"platform_user": [{"id": 1, "name": "张三"}]}},
+    "cases": [
+      {
+        "name": "查询存在的用户",
+        "params": {"id": 1},
+        "expect": {"code": 200, "data": {"name": "张三"}}
+      },
+      {
+        "name": "修改用户名",
+        "params": {"id": 1, "name": "李四", "loginName": "lisi"},
+        "expectEntities": {"platform_user": [{"id": 1, "name": "李四"}]}
+      },
+      {"name": "缺少参数", "params": {}, "expectError": "id is required"}
+    ]
+  }
+
+mocks 的格式与 api run --mocks 相同，用例中的 mocks 与文件级的合并。expect 与
+expectEntities 只比较其中列出的字段，"exact": true 时要求完全一致。.api.test.js 的
+最后一个表达式为同样结构的对象。
+
+不指定路径时测试 api/ 下的所有用例，也可以指定目录、用例文件或 API 脚本。用例默认
+在本地运行时中执行；--remote 把本地脚本发送到当前 profile 的开发环境执行，此时使用
+平台上的数据，mocks 与 expectEntities 不生效。有用例失败时命令以非零状态退出。
+
+示例:
+  geelato api test
+  geelato api test api/user/getList.api.js
+  geelato api test --run 分页
+  geelato api test --junit reports/api-test.xml
+  geelato api test --remote`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			logger.Info("API test functionality not yet implemented")
-			return nil
+			return testAPIs(args)
 		},
 	}
+
+	cmd.Flags().BoolVar(&apiTestRemote, "remote", false, "Run the scripts on the dev platform instead of the local runtime")
+	cmd.Flags().StringVar(&apiTestJUnit, "junit", "", "Write a JUnit XML report to this file")
+	cmd.Flags().StringVar(&apiTestRun, "run", "", "Only run test cases whose name contains this text")
+	cmd.Flags().DurationVar(&apiTestTimeout, "timeout", script.DefaultTimeout, "Maximum execution time of each test case")
+
+	return cmd
+}
+
+func testAPIs(paths []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if len(paths) == 0 {
+		if _, err := os.Stat(filepath.Join(cwd, "geelato.json")); os.IsNotExist(err) {
+			return fmt.Errorf("current directory is not a valid Geelato application")
+		}
+		paths = []string{"api"}
+		if _, err := os.Stat("api"); os.IsNotExist(err) {
+			paths = nil
+		}
+	}
+
+	files, err := script.FindFixtures(paths)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		logger.Info("No API tests found. Add <name>.api.test.json next to an API script.")
+		return nil
+	}
+
+	var remote script.Executor
+	if apiTestRemote {
+		if remote, err = remoteAPIExecutor(cwd); err != nil {
+			return err
+		}
+		logger.Info("Running API tests on the dev platform; mocks and expectEntities are ignored.")
+	}
+
+	ctx := context.Background()
+	var suites []script.SuiteResult
+	passed, failed := 0, 0
+	for _, file := range files {
+		suite := runFixture(ctx, file, remote)
+		suites = append(suites, suite)
+		for _, c := range suite.Cases {
+			if c.Passed() {
+				passed++
+			} else {
+				failed++
+			}
+		}
+		if suite.Error != "" {
+			failed++
+		}
+	}
+
+	if apiTestJUnit != "" {
+		if dir := filepath.Dir(apiTestJUnit); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		if err := script.WriteJUnit(apiTestJUnit, suites); err != nil {
+			return fmt.Errorf("failed to write JUnit report: %w", err)
+		}
+		logger.Infof("JUnit report written to %s", apiTestJUnit)
+	}
+
+	logger.Info("")
+	if failed > 0 {
+		return fmt.Errorf("%d of %d API tests failed", failed, passed+failed)
+	}
+	logger.Successf("%d API tests passed", passed)
+	return nil
+}
+
+// runFixture 加载并执行一个用例文件，输出每个用例的结果与失败用例的差异和日志
+func runFixture(ctx context.Context, file string, remote script.Executor) script.SuiteResult {
+	suite := script.SuiteResult{Fixture: filepath.ToSlash(file)}
+
+	fixture, err := script.LoadFixture(file, apiTestTimeout)
+	if err != nil {
+		suite.Error = err.Error()
+		logger.Errorf("%v", err)
+		return suite
+	}
+	scriptPath := fixture.ScriptPath()
+	suite.API = filepath.ToSlash(scriptPath)
+
+	src, err := os.ReadFile(scriptPath)
+	if err != nil {
+		suite.Error = fmt.Sprintf("failed to read API script: %v", err)
+		logger.Errorf("%s: %s", suite.Fixture, suite.Error)
+		return suite
+	}
+
+	exec := remote
+	if exec == nil {
+		exec = script.LocalExecutor(apiTestTimeout)
+	}
+	suite.Cases = script.RunCases(ctx, fixture, src, apiTestRun, exec)
+	if len(suite.Cases) == 0 {
+		return suite
+	}
+
+	logger.Infof("%s", suite.API)
+	for _, c := range suite.Cases {
+		if c.Passed() {
+			logger.Infof("  PASS %s (%s)", c.Name, c.Duration.Round(time.Millisecond))
+			continue
+		}
+		logger.Errorf("  FAIL %s (%s)", c.Name, c.Duration.Round(time.Millisecond))
+		if c.Error != "" {
+			logger.Errorf("       %s", c.Error)
+		}
+		if len(c.Changes) > 0 {
+			cmdsync.PrintJSONDiff(os.Stdout, "expected", "actual", c.Changes)
+		}
+		for _, l := range c.Logs {
+			logger.Infof("       [%s] %s", l.Level, l.Message)
+		}
+	}
+	return suite
+}
+
+// remoteAPIExecutor 把本地脚本发送到当前 profile 的开发环境执行
+func remoteAPIExecutor(cwd string) (script.Executor, error) {
+	endpoint, err := app.ResolveEndpoint(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve platform endpoint: %w", err)
+	}
+	client := platform.NewClientWithEndpoint(endpoint)
+	appID := endpoint.AppCode
+	if appConfig, err := app.LoadAppConfig(cwd); err == nil {
+		if id := app.GetAppIDFromConfig(appConfig); id != "" {
+			appID = id
+		}
+	}
+
+	return func(ctx context.Context, f *script.Fixture, src []byte, c *script.Case, params map[string]interface{}) (*script.Result, error) {
+		ctx, cancel := context.WithTimeout(ctx, apiTestTimeout)
+		defer cancel()
+
+		resp, err := client.RunAPIScript(ctx, &platform.APIRunRequest{
+			AppID:  appID,
+			Path:   filepath.ToSlash(f.ScriptPath()),
+			Script: string(src),
+			Params: params,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result := &script.Result{Value: resp.Result}
+		for _, l := range resp.Logs {
+			result.Logs = append(result.Logs, script.LogEntry{Level: l.Level, Message: l.Message})
+		}
+		return result, nil
+	}, nil
 }
 
+var (
+	apiRunParams     []string
+	apiRunParamsFile string
+	apiRunMocksFile  string
+	apiRunTimeout    time.Duration
+)
+
 func NewApiRunCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "run <api-file>",
 		Short: "run(运行API)",
-		Long:  `运行 API 脚本`,
-		Args:  cobra.ExactArgs(1),
+		Long: `在本地运行 API 脚本，结果以 JSON 输出
+
+脚本在内置的 JavaScript 引擎中运行，不需要推送到平台。$params 来自 --params 文件
+与 --param 参数（--param 优先，按 @param 声明的类型转换，缺失时使用 default）。
+$db、$session、$cache 与 $http 只访问 --mocks 文件中的模拟数据：
+
+  {
+    "entities": {"platform_user": [{"id": 1, "name": "张三"}]},
+    "queries":  [{"sql": "FROM platform_user u JOIN", "result": [{"total": 1}]}],
+    "session":  {"userId": 1},
+    "cache":    {"user:1": {"id": 1}},
+    "http":     [{"method": "GET", "url": "https://api.example.com/*", "data": {}}]
+  }
+
+$db 在 entities 上执行单表的 SELECT、INSERT、UPDATE 与 DELETE；包含 queries 中 sql
+片段的查询直接返回 result。$log 与 console 的输出以及运行时提示写到标准错误。
+
+<api-file> 可以是脚本路径，也可以是 api/ 下的 API 名称。
+
+示例:
+  geelato api run api/user/getDetail.api.js --param id=1 --mocks mocks.json
+  geelato api run getList --param pageNum=2 --param keyword=张
+  geelato api run saveUser --params params.json`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			logger.Info("API run functionality not yet implemented")
-			return nil
+			return runAPI(args[0])
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&apiRunParams, "param", nil, "Request parameter as key=value (repeatable)")
+	cmd.Flags().StringVar(&apiRunParamsFile, "params", "", "JSON file with request parameters")
+	cmd.Flags().StringVar(&apiRunMocksFile, "mocks", "", "JSON file with mocked entities, queries, session, cache and HTTP responses")
+	cmd.Flags().DurationVar(&apiRunTimeout, "timeout", script.DefaultTimeout, "Maximum execution time")
+
+	return cmd
+}
+
+func runAPI(target string) error {
+	path, err := resolveAPIScript(target)
+	if err != nil {
+		return err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read API script: %w", err)
+	}
+
+	params := make(map[string]interface{})
+	if apiRunParamsFile != "" {
+		data, err := os.ReadFile(apiRunParamsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read parameters: %w", err)
+		}
+		if err := json.Unmarshal(data, &params); err != nil {
+			return fmt.Errorf("failed to parse %s: expected a JSON object: %w", apiRunParamsFile, err)
+		}
+	}
+	specs := script.ParseParams(src)
+	if err := script.ParseParamArgs(specs, apiRunParams, params); err != nil {
+		return err
+	}
+	missing, err := script.ApplyDefaults(specs, params)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "[warn] missing required parameters: %s\n", strings.Join(missing, ", "))
+	}
+
+	var mocks *script.Mocks
+	if apiRunMocksFile != "" {
+		if mocks, err = script.LoadMocks(apiRunMocksFile); err != nil {
+			return err
+		}
+	}
+
+	result, err := script.Run(context.Background(), filepath.ToSlash(path), src, script.Options{
+		Params:  params,
+		Mocks:   mocks,
+		Timeout: apiRunTimeout,
+		Log: func(entry script.LogEntry) {
+			fmt.Fprintf(os.Stderr, "[%s] %s\n", entry.Level, entry.Message)
 		},
+	})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result.Value)
+}
+
+// resolveAPIScript 按路径或 API 名称查找 JavaScript API 脚本，名称在 api/ 下递归查找 <name>.api.js
+func resolveAPIScript(target string) (string, error) {
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		if !strings.HasSuffix(target, ".js") {
+			return "", fmt.Errorf("only JavaScript API scripts can be run locally: %s", target)
+		}
+		return target, nil
+	}
+
+	name := strings.TrimSuffix(filepath.Base(target), ".api.js") + ".api.js"
+	var matches []string
+	filepath.WalkDir("api", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && d.Name() == name {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("API script not found: %s", target)
+	case 1:
+		return matches[0], nil
 	}
+	return "", fmt.Errorf("%s matches several API scripts, pass the path instead: %s", target, strings.Join(matches, ", "))
 }
@@ -4,11 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 
-	"github.com/spf13/cobra"
-	"github.com/geelato/cli/internal/app"
 	"github.com/geelato/cli/pkg/logger"
+	"github.com/spf13/cobra"
 )
 
 var appInfoCmd = &cobra.Command{
@@ -41,11 +39,11 @@ func runInfo() error {
 
 	var config struct {
 		Meta struct {
-			Version    string `json:"version"`
-			AppID     string `json:"appId"`
-			Name      string `json:"name"`
+			Version     string `json:"version"`
+			AppID       string `json:"appId"`
+			Name        string `json:"name"`
 			Description string `json:"description"`
-			CreatedAt string `json:"createdAt"`
+			CreatedAt   string `json:"createdAt"`
 		} `json:"meta"`
 	}
 
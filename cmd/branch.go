@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geelato/cli/internal/sync"
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/geelato/cli/pkg/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	branchFrom   string
+	branchSwitch bool
+	branchYes    bool
+)
+
+func NewBranchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "Manage branches(管理云端分支)",
+		Long: `Manage application branches on the cloud platform
+
+Branches let several teams work on the same application in parallel. The
+branch the working tree tracks is recorded in .geelato/sync-state.json and
+used by push, pull, diff, log and rollback; push, pull and clone also accept
+--branch to target another branch.
+
+Example:
+  geelato branch list
+  geelato branch create feature-order --switch
+  geelato branch switch main
+  geelato branch delete feature-order`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBranchList()
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List branches",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBranchList()
+		},
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a branch from the current version",
+		Long: `Create a branch on the platform
+
+Without --from the branch starts at the version the working tree is synced
+with on the current branch; with --from it starts at the latest version of
+the given branch.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBranchCreate(args[0])
+		},
+	}
+	createCmd.Flags().StringVar(&branchFrom, "from", "", "Branch to create from (default: current branch)")
+	createCmd.Flags().BoolVar(&branchSwitch, "switch", false, "Switch to the new branch after creating it")
+
+	switchCmd := &cobra.Command{
+		Use:   "switch <name>",
+		Short: "Switch the working tree to another branch",
+		Long: `Switch the working tree to the latest version of another branch
+
+Like checkout, switch refuses to run while there are local changes that
+have not been pushed, or unresolved conflicts.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBranchSwitch(args[0])
+		},
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a branch on the platform",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBranchDelete(args[0])
+		},
+	}
+	deleteCmd.Flags().BoolVarP(&branchYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	cmd.AddCommand(listCmd, createCmd, switchCmd, deleteCmd)
+	return cmd
+}
+
+func runBranchList() error {
+	_, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	branches, err := svc.ListBranches(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := svc.Branch()
+	if len(branches) == 0 {
+		logger.Infof("No branches on the platform yet; the working tree tracks %s.", current)
+		return nil
+	}
+
+	for _, b := range branches {
+		marker := " "
+		if b.Name == current {
+			marker = "*"
+		}
+		from := ""
+		if b.From != "" {
+			from = "from " + b.From
+		}
+		fmt.Printf("%s %-20s %-10s %s  %s\n", marker, b.Name, b.Version, formatVersionTime(b.CreatedAt), from)
+	}
+	return nil
+}
+
+func runBranchCreate(name string) error {
+	_, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	branch, err := svc.CreateBranch(ctx, name, branchFrom)
+	if err != nil {
+		return err
+	}
+	logger.Successf("Created branch %s at version %s", branch.Name, branch.Version)
+
+	if !branchSwitch {
+		logger.Infof("Run 'geelato branch switch %s' to start working on it.", branch.Name)
+		return nil
+	}
+	return switchBranch(ctx, svc, name)
+}
+
+func runBranchSwitch(name string) error {
+	_, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	return switchBranch(ctx, svc, name)
+}
+
+func switchBranch(ctx context.Context, svc *sync.SyncService, name string) error {
+	if name == svc.Branch() {
+		logger.Infof("Already on branch %s.", name)
+		return nil
+	}
+
+	result, err := svc.SwitchBranch(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range result.Updated {
+		logger.Infof("  U %s", path)
+	}
+	for _, path := range result.Deleted {
+		logger.Infof("  D %s", path)
+	}
+	logger.Successf("Switched to branch %s (version %s)", name, result.Version)
+	return nil
+}
+
+func runBranchDelete(name string) error {
+	_, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	if !branchYes {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Delete branch %s on the platform?", name), false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			logger.Info("Delete cancelled.")
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := svc.DeleteBranch(ctx, name); err != nil {
+		return err
+	}
+	logger.Successf("Deleted branch %s", name)
+	return nil
+}
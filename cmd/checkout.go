@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/geelato/cli/internal/sync"
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+func NewCheckoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "checkout <version>",
+		Short: "Checkout a version(将工作区恢复为云端指定版本)",
+		Long: `Restore the working tree to a cloud version
+
+Files are replaced with their content in that version, and files the
+version does not contain are removed. Checkout refuses to run while there
+are local changes that have not been pushed, or unresolved conflicts.
+
+After checking out an older version, 'geelato pull' returns to the latest
+version. To publish an older version again, use 'geelato rollback'.
+
+Example:
+  geelato log
+  geelato checkout v12`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheckout(args[0])
+		},
+	}
+}
+
+func runCheckout(version string) error {
+	cwd, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	state, err := sync.LoadState(cwd)
+	if err != nil {
+		return err
+	}
+	changes, err := sync.DetectChanges(cwd, state)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		logger.Errorf("Cannot checkout %s: %d local changes have not been pushed", version, len(changes))
+		for _, change := range changes {
+			logger.Errorf("  %s %s", changeLetter(change.Type), change.Path)
+		}
+		logger.Info("Push them with 'geelato push' or revert them before checking out another version.")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	result, err := svc.Checkout(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Updated)+len(result.Deleted) == 0 {
+		logger.Infof("Already at version %s.", result.Version)
+		return nil
+	}
+
+	for _, path := range result.Updated {
+		logger.Infof("  U %s", path)
+	}
+	for _, path := range result.Deleted {
+		logger.Infof("  D %s", path)
+	}
+	logger.Successf("Checked out version %s (%d updated, %d deleted)", result.Version,
+		len(result.Updated), len(result.Deleted))
+	return nil
+}
@@ -1,18 +1,18 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/internal/sync"
+	"github.com/geelato/cli/internal/transport"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +20,7 @@ import (
 var (
 	cloneOutput      string
 	cloneVersion     string
+	cloneBranch      string
 	cloneSkipExtract bool
 )
 
@@ -42,17 +43,20 @@ URL 格式：
 示例：
   geelato clone http://localhost:8080/default/myapp       # 克隆应用到 myapp 目录
   geelato clone http://localhost:8080/mytenant/myapp      # 指定租户
-  geelato clone http://localhost:8080/default/myapp -o ./projects  # 指定输出目录`,
+  geelato clone http://localhost:8080/default/myapp -o ./projects  # 指定输出目录
+  geelato clone http://localhost:8080/default/myapp --branch feature-order  # 克隆指定分支
+  geelato --profile prod clone http://localhost:8080/default/myapp # 使用 prod profile 的地址和凭据`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repoURL := args[0]
-		return runClone(repoURL, cloneOutput, cloneVersion, cloneSkipExtract)
+		return runClone(repoURL, cloneOutput, cloneVersion, cloneBranch, cloneSkipExtract)
 	},
 }
 
 func init() {
 	cloneCmd.Flags().StringVarP(&cloneOutput, "output", "o", "", "Output directory (default: app code)")
 	cloneCmd.Flags().StringVar(&cloneVersion, "version", "latest", "App version")
+	cloneCmd.Flags().StringVar(&cloneBranch, "branch", "", "Branch to clone (default: main)")
 	cloneCmd.Flags().BoolVar(&cloneSkipExtract, "skip-extract", false, "Skip extracting zip file")
 }
 
@@ -219,16 +223,20 @@ type WorkflowData struct {
 	Definition string `json:"definition"`
 }
 
-func runClone(repoURL, outputDir, version string, skipExtract bool) error {
+func runClone(repoURL, outputDir, version, branch string, skipExtract bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	tenant, appCode, apiURL, err := parseCloneURL(repoURL)
+	endpoint, err := config.Get().ResolveEndpoint(repoURL)
 	if err != nil {
 		return fmt.Errorf("invalid URL format: %w", err)
 	}
+	tenant, appCode, apiURL := endpoint.Tenant, endpoint.AppCode, endpoint.URL
 
 	logger.Infof("Parsing URL: tenant=%s, appCode=%s, apiURL=%s", tenant, appCode, apiURL)
+	if endpoint.Profile != "" {
+		logger.Infof("Using profile: %s", endpoint.Profile)
+	}
 
 	if outputDir == "" {
 		outputDir = appCode
@@ -241,60 +249,28 @@ func runClone(repoURL, outputDir, version string, skipExtract bool) error {
 		"tenant":  tenant,
 		"version": version,
 	}
-	bodyBytes, _ := json.Marshal(body)
-
-	cloneURL := fmt.Sprintf("%s/api/cli/app/clone", apiURL)
-	logger.Infof("Requesting: %s", cloneURL)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cloneURL, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("clone failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	var result struct {
-		Code int             `json:"code"`
-		Data json.RawMessage `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	if branch != "" {
+		body["branch"] = branch
 	}
 
-	logger.Infof("Response code: %d, data length: %d", result.Code, len(result.Data))
+	logger.Infof("Requesting: %s/api/cli/app/clone", apiURL)
 
-	// 解析外层结构，检查是否有 format 字段
-	var outerData struct {
-		Format string          `json:"format"`
-		Data   json.RawMessage `json:"data"`
-	}
-	if err := json.Unmarshal(result.Data, &outerData); err != nil {
-		return fmt.Errorf("failed to parse outer data: %w", err)
-	}
-
-	// 如果有 format 字段，说明数据被包装了一层
-	var appDataBytes []byte
-	if outerData.Format != "" && outerData.Data != nil {
-		logger.Infof("Found nested format: %s", outerData.Format)
-		appDataBytes = outerData.Data
-	} else {
-		appDataBytes = result.Data
+	// 克隆是只读操作，网络错误或网关错误时可以安全重试
+	client := transport.NewWithEndpoint(endpoint)
+	resp, err := client.Do(ctx, transport.Request{
+		Method:     http.MethodPost,
+		Path:       "/api/cli/app/clone",
+		Body:       body,
+		Timeout:    5 * time.Minute,
+		Idempotent: true,
+	})
+	if err != nil {
+		return fmt.Errorf("clone failed: %w", err)
 	}
 
 	var appData CloneResponseData
-	if err := json.Unmarshal(appDataBytes, &appData); err != nil {
-		return fmt.Errorf("failed to parse app data: %w", err)
+	if err := resp.Decode(&appData); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	logger.Infof("Parsed: entities=%d, pages=%d, apis=%d, workflows=%d",
@@ -305,41 +281,24 @@ func runClone(repoURL, outputDir, version string, skipExtract bool) error {
 		return fmt.Errorf("failed to render and save: %w", err)
 	}
 
-	logger.Success("Clone completed successfully!")
-	logger.Infof("App cloned to: %s", outputDir)
-	return nil
-}
-
-func parseCloneURL(repoURL string) (tenant, appCode, apiURL string, err error) {
-	repoURL = strings.TrimSpace(repoURL)
-
-	if !strings.HasPrefix(repoURL, "http://") && !strings.HasPrefix(repoURL, "https://") {
-		repoURL = "http://" + repoURL
-	}
-
-	u, err := url.Parse(repoURL)
+	// 记录克隆的版本、分支与文件基线，之后的 push 据此检测冲突，pull 据此三方合并
+	svc, err := sync.NewSyncService(outputDir, endpoint)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to parse URL: %w", err)
+		return err
 	}
-
-	path := strings.TrimPrefix(u.Path, "/")
-	path = strings.TrimSuffix(path, "/")
-
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
-		return "", "", "", fmt.Errorf("URL path should contain tenant and app code (e.g., /tenant/app-code)")
+	svc.SetBranch(branch)
+	if appData.Version != "" {
+		version = appData.Version
+	} else if version == "latest" {
+		version = ""
 	}
-
-	tenant = parts[0]
-	appCode = parts[1]
-
-	if tenant == "" || appCode == "" {
-		return "", "", "", fmt.Errorf("tenant and app code cannot be empty")
+	if err := svc.Track(ctx, version); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
 	}
 
-	apiURL = u.Scheme + "://" + u.Host
-
-	return tenant, appCode, apiURL, nil
+	logger.Success("Clone completed successfully!")
+	logger.Infof("App cloned to: %s", outputDir)
+	return nil
 }
 
 type CloneManager struct {
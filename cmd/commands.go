@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/geelato/cli/cmd/config"
+	cmdsync "github.com/geelato/cli/cmd/sync"
 	"github.com/geelato/cli/cmd/workflow"
 	"github.com/spf13/cobra"
 )
@@ -17,7 +18,17 @@ var (
 	pushCmd     *cobra.Command
 	pullCmd     *cobra.Command
 	diffCmd     *cobra.Command
+	logCmd      *cobra.Command
+	showCmd     *cobra.Command
+	checkoutCmd *cobra.Command
+	rollbackCmd *cobra.Command
+	branchCmd   *cobra.Command
+	mergeCmd    *cobra.Command
 	pageCmd     *cobra.Command
+	loginCmd    *cobra.Command
+	logoutCmd   *cobra.Command
+	syncCmd     *cobra.Command
+	watchCmd    *cobra.Command
 )
 
 func init() {
@@ -31,7 +42,17 @@ func init() {
 	pushCmd = NewPushCmd()
 	pullCmd = NewPullCmd()
 	diffCmd = NewDiffCmd()
+	logCmd = NewLogCmd()
+	showCmd = NewShowCmd()
+	checkoutCmd = NewCheckoutCmd()
+	rollbackCmd = NewRollbackCmd()
+	branchCmd = NewBranchCmd()
+	mergeCmd = NewMergeCmd()
 	pageCmd = NewPageCmd()
+	loginCmd = NewLoginCmd()
+	logoutCmd = NewLogoutCmd()
+	syncCmd = cmdsync.NewSyncCmd()
+	watchCmd = NewWatchCmd()
 }
 
 func NewMcpCmd() *cobra.Command {
@@ -3,31 +3,62 @@ package config
 import (
 	"fmt"
 
+	"github.com/geelato/cli/internal/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var listShowSource bool
+
 var configListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list(列出所有配置)",
-	Long:  `列出当前配置的所有选项及其值`,
+	Long: `列出当前生效的所有配置项及其值。
+
+配置按以下优先级从低到高合并：
+  default  内置默认值
+  global   全局配置文件 ~/.geelato/geelato.yaml（或 --config 指定）
+  project  项目配置文件 .geelato/config.yaml
+  env      GEELATO_* 环境变量，如 GEELATO_API_URL
+  flag     命令行参数，如 --api-url
+
+示例：
+  geelato config list
+  geelato config list --source`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg := viper.ConfigFileUsed()
-		if cfg == "" {
-			cfg = "未使用配置文件"
+		cfgFile := viper.ConfigFileUsed()
+		if cfgFile == "" {
+			cfgFile = config.GlobalConfigPath()
 		}
 
 		fmt.Println()
-		fmt.Printf("配置文件: %s\n", cfg)
+		fmt.Printf("配置文件: %s\n", cfgFile)
 		fmt.Println()
 		fmt.Println("当前配置:")
 		fmt.Println("--------")
 
-		settings := viper.AllSettings()
-		for key, value := range settings {
-			fmt.Printf("  %s: %v\n", key, value)
+		cfg := config.Get()
+		if cfg == nil {
+			fmt.Println("  配置未加载")
+			return nil
+		}
+
+		for _, s := range cfg.Settings() {
+			if !listShowSource {
+				fmt.Printf("  %s: %v\n", s.Key, s.Value)
+				continue
+			}
+			if s.File != "" {
+				fmt.Printf("  %s: %v  [%s: %s]\n", s.Key, s.Value, s.Source, s.File)
+			} else {
+				fmt.Printf("  %s: %v  [%s]\n", s.Key, s.Value, s.Source)
+			}
 		}
 
 		return nil
 	},
 }
+
+func init() {
+	configListCmd.Flags().BoolVarP(&listShowSource, "source", "s", false, "显示每个配置项的来源层级")
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/geelato/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileURL     string
+	profileKey     string
+	profileTimeout int
+	profileTenant  string
+	profileUse     bool
+
+	profileProxy   string
+	profileNoProxy string
+	profileTLS     config.TLSConfig
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "profile(管理服务器配置)",
+	Long: `管理多个 Geelato 平台服务器配置（profile），如 dev、staging、prod。
+
+每个 profile 包含独立的 API 地址、密钥、超时时间、租户以及代理与证书配置，保存在全局配置文件中。
+未在 profile 中配置的代理与证书沿用全局 api.proxy、api.tls.* 配置。
+使用全局参数 --profile 或 GEELATO_PROFILE 环境变量可临时切换 profile。
+
+示例：
+  geelato config profile add dev --url http://dev.example.com:8080 --tenant default
+  geelato config profile add onprem --url https://geelato.corp.local --proxy socks5://10.0.0.1:1080 --ca-file ~/certs/corp-ca.pem
+  geelato config profile use dev
+  geelato config profile list
+  geelato config profile remove dev
+  geelato --profile prod push`,
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "add(新增或更新 profile)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if profileURL == "" {
+			return fmt.Errorf("必须通过 --url 指定平台地址")
+		}
+
+		if u, err := url.Parse(profileURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("平台地址无效: %s", profileURL)
+		}
+
+		path := config.Get().GlobalFile()
+		err := config.SaveProfile(path, name, config.ProfileConfig{
+			API: config.APIConfig{
+				URL:     profileURL,
+				Key:     profileKey,
+				Timeout: profileTimeout,
+				Proxy:   profileProxy,
+				NoProxy: profileNoProxy,
+				TLS:     profileTLS,
+			},
+			Tenant: profileTenant,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("profile 已保存: %s\n", name)
+
+		if profileUse {
+			if err := config.UseProfile(path, name); err != nil {
+				return err
+			}
+			fmt.Printf("当前 profile: %s\n", name)
+		}
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "use(切换当前 profile)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.UseProfile(config.Get().GlobalFile(), args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("当前 profile: %s\n", args[0])
+		return nil
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list(列出所有 profile)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Get()
+		names := cfg.ProfileNames()
+		if len(names) == 0 {
+			fmt.Println("尚未配置 profile")
+			fmt.Println("使用 'geelato config profile add <name> --url <url>' 创建")
+			return nil
+		}
+
+		active, _ := cfg.ActiveProfile()
+		for _, name := range names {
+			p := cfg.Profiles[name]
+			marker := " "
+			if active != nil && active.Name == name {
+				marker = "*"
+			}
+			tenant := p.Tenant
+			if tenant == "" {
+				tenant = "-"
+			}
+			fmt.Printf("%s %-12s %-40s tenant=%s\n", marker, name, p.API.URL, tenant)
+		}
+		return nil
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "remove(删除 profile)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.RemoveProfile(config.Get().GlobalFile(), args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("profile 已删除: %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	profileAddCmd.Flags().StringVar(&profileURL, "url", "", "平台 API 地址，如 http://localhost:8080")
+	profileAddCmd.Flags().StringVar(&profileKey, "key", "", "平台 API 密钥")
+	profileAddCmd.Flags().IntVar(&profileTimeout, "timeout", 0, "请求超时秒数")
+	profileAddCmd.Flags().StringVar(&profileTenant, "tenant", "", "默认租户")
+	profileAddCmd.Flags().BoolVar(&profileUse, "use", false, "保存后设为当前 profile")
+	profileAddCmd.Flags().StringVar(&profileProxy, "proxy", "", "代理地址，支持 http://、https://、socks5://")
+	profileAddCmd.Flags().StringVar(&profileNoProxy, "no-proxy", "", "不经过代理的主机，逗号分隔")
+	profileAddCmd.Flags().StringVar(&profileTLS.CAFile, "ca-file", "", "自定义 CA 证书文件（PEM）")
+	profileAddCmd.Flags().StringVar(&profileTLS.CertFile, "cert-file", "", "双向 TLS 客户端证书文件（PEM）")
+	profileAddCmd.Flags().StringVar(&profileTLS.KeyFile, "key-file", "", "双向 TLS 客户端私钥文件（PEM）")
+	profileAddCmd.Flags().BoolVar(&profileTLS.InsecureSkipVerify, "insecure-skip-verify", false, "跳过服务端证书校验（不安全，仅限测试环境）")
+
+	profileCmd.AddCommand(profileAddCmd, profileUseCmd, profileListCmd, profileRemoveCmd)
+	ConfigCmd.AddCommand(profileCmd)
+}
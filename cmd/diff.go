@@ -1,31 +1,81 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/geelato/cli/internal/config"
+	cmdsync "github.com/geelato/cli/cmd/sync"
+	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/model"
 	"github.com/geelato/cli/internal/sync"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
+var (
+	diffNameOnly bool
+	diffStat     bool
+	diffJSON     bool
+	diffUnified  bool
+	diffVersion  string
+)
+
 func NewDiffCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "diff",
+	cmd := &cobra.Command{
+		Use:   "diff [path...]",
 		Short: "Show differences(显示本地与云端的差异)",
-		Long: `Show differences between local and cloud
+		Long: `Show differences between local files and the cloud version
+
+The cloud version (latest unless --version is given) is downloaded and
+compared with local files, showing what a push would change. Entity
+metadata (*.define.json, *.columns.json, *.fk.json, *.check.json) is shown
+as a semantic diff, e.g. "column ` + "`email`" + ` length 64→128"; other JSON files
+are compared key by key and everything else as a unified text diff.
 
 Example:
-  geelato diff`,
+  geelato diff
+  geelato diff meta/User api/user/saveUser.api.js
+  geelato diff --stat
+  geelato diff --name-only
+  geelato diff --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDiff()
+			return runDiff(args)
 		},
 	}
+
+	cmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "Show only names of changed files")
+	cmd.Flags().BoolVar(&diffStat, "stat", false, "Show per-file insertion and deletion counts")
+	cmd.Flags().BoolVar(&diffJSON, "json", false, "Output differences as JSON")
+	cmd.Flags().BoolVar(&diffUnified, "unified", false, "Always show line-level unified diffs, also for JSON files")
+	cmd.Flags().StringVar(&diffVersion, "version", "", "Cloud version to compare with (default: latest)")
+
+	return cmd
+}
+
+// fileDiffView 单个文件差异的输出结构
+type fileDiffView struct {
+	Path      string   `json:"path"`
+	Status    string   `json:"status"`
+	Binary    bool     `json:"binary,omitempty"`
+	Additions int      `json:"additions"`
+	Deletions int      `json:"deletions"`
+	Changes   []string `json:"changes,omitempty"`
+	Patch     string   `json:"patch,omitempty"`
 }
 
-func runDiff() error {
-	logger.Info("Checking differences between local and cloud...")
+func runDiff(args []string) error {
+	if diffJSON {
+		logger.SetLevel(logger.WarnLevel)
+	} else {
+		logger.Info("Checking differences between local and cloud...")
+	}
 
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -40,31 +90,197 @@ func runDiff() error {
 		return nil
 	}
 
-	cfg := config.Get()
-	if cfg == nil {
-		logger.Error("Configuration not loaded. Please run 'geelato config set api.url <url>' first.")
-		return nil
+	paths, err := diffPaths(cwd, args)
+	if err != nil {
+		return err
 	}
 
-	svc, err := sync.NewSyncService(cwd, cfg.API.URL, cfg.API.Key)
+	endpoint, err := app.ResolveEndpoint(cwd)
 	if err != nil {
+		logger.Errorf("Failed to resolve platform endpoint: %v", err)
 		return err
 	}
 
-	diff, err := svc.GetDiff()
+	svc, err := sync.NewSyncService(cwd, endpoint)
 	if err != nil {
 		return err
 	}
 
-	if len(diff.Added) == 0 && len(diff.Modified) == 0 && len(diff.Deleted) == 0 {
-		logger.Info("No differences found. Local and cloud are in sync.")
-		return nil
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	version, diffs, err := svc.Diff(ctx, diffVersion, paths)
+	if err != nil {
+		return err
 	}
 
-	logger.Info("Differences found:")
-	logger.Infof("  Added: %d", len(diff.Added))
-	logger.Infof("  Modified: %d", len(diff.Modified))
-	logger.Infof("  Deleted: %d", len(diff.Deleted))
+	switch {
+	case diffJSON:
+		views := make([]fileDiffView, 0, len(diffs))
+		for _, d := range diffs {
+			views = append(views, buildDiffView(d))
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{
+			"version": version,
+			"files":   views,
+		})
+	case len(diffs) == 0:
+		logger.Infof("No differences found. Local and cloud (version %s) are in sync.", version)
+		return nil
+	case diffNameOnly:
+		for _, d := range diffs {
+			fmt.Println(d.Path)
+		}
+		return nil
+	case diffStat:
+		printDiffStat(diffs)
+		return nil
+	}
 
+	for _, d := range diffs {
+		printFileDiff(d, version)
+	}
 	return nil
 }
+
+// diffPaths 将命令行参数转换为相对应用根目录的路径
+func diffPaths(cwd string, args []string) ([]string, error) {
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(cwd, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("path %s is outside the application", arg)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths, nil
+}
+
+func buildDiffView(d sync.FileDiff) fileDiffView {
+	view := fileDiffView{Path: d.Path, Status: string(d.Type)}
+	if sync.IsBinary(d.Local) || sync.IsBinary(d.Remote) {
+		view.Binary = true
+		return view
+	}
+
+	hunks := sync.UnifiedDiff(d.Remote, d.Local, 3)
+	view.Additions, view.Deletions = countLines(hunks)
+
+	var patch bytes.Buffer
+	for _, hunk := range hunks {
+		patch.WriteString(hunk.Header() + "\n")
+		for _, line := range hunk.Lines {
+			patch.WriteByte(line.Op)
+			patch.WriteString(line.Text + "\n")
+		}
+	}
+	view.Patch = patch.String()
+
+	if model.IsMetaFile(d.Path) {
+		if changes, err := model.DiffMeta(d.Path, d.Remote, d.Local); err == nil {
+			for _, c := range changes {
+				view.Changes = append(view.Changes, c.String())
+			}
+		}
+	} else if isJSONFile(d.Path) && d.Local != nil && d.Remote != nil {
+		if changes, err := sync.DiffJSON(d.Remote, d.Local); err == nil {
+			for _, c := range changes {
+				view.Changes = append(view.Changes, c.String())
+			}
+		}
+	}
+	return view
+}
+
+// printFileDiff 输出单个文件相对云端版本的差异
+func printFileDiff(d sync.FileDiff, version string) {
+	oldName := fmt.Sprintf("cloud/%s (%s)", d.Path, version)
+	newName := "local/" + d.Path
+	switch d.Type {
+	case sync.ChangeAdded:
+		oldName = "/dev/null"
+	case sync.ChangeDeleted:
+		newName = "/dev/null"
+	}
+	printContentDiff(d.Path, oldName, newName, d.Remote, d.Local)
+}
+
+// printContentDiff 输出文件两个版本间的差异：元数据为语义差异，其余 JSON 按键比较，其他文件为统一格式。
+// old 或 new 为 nil 表示该侧文件不存在
+func printContentDiff(p, oldName, newName string, old, new []byte) {
+	if sync.IsBinary(old) || sync.IsBinary(new) {
+		fmt.Printf("Binary files %s and %s differ\n", oldName, newName)
+		return
+	}
+
+	if !diffUnified {
+		if model.IsMetaFile(p) {
+			if changes, err := model.DiffMeta(p, old, new); err == nil && len(changes) > 0 {
+				cmdsync.PrintMetaDiff(os.Stdout, oldName, newName, changes)
+				return
+			}
+		} else if isJSONFile(p) && old != nil && new != nil {
+			if changes, err := sync.DiffJSON(old, new); err == nil && len(changes) > 0 {
+				cmdsync.PrintJSONDiff(os.Stdout, oldName, newName, changes)
+				return
+			}
+		}
+	}
+
+	cmdsync.PrintUnifiedDiff(os.Stdout, oldName, newName, sync.UnifiedDiff(old, new, 3))
+}
+
+func printDiffStat(diffs []sync.FileDiff) {
+	width := 0
+	for _, d := range diffs {
+		if len(d.Path) > width {
+			width = len(d.Path)
+		}
+	}
+
+	var additions, deletions int
+	for _, d := range diffs {
+		if sync.IsBinary(d.Local) || sync.IsBinary(d.Remote) {
+			fmt.Printf(" %-*s | Bin\n", width, d.Path)
+			continue
+		}
+		add, del := countLines(sync.UnifiedDiff(d.Remote, d.Local, 0))
+		additions += add
+		deletions += del
+		fmt.Printf(" %-*s | %4d %s%s\n", width, d.Path, add+del, strings.Repeat("+", scaleStat(add)), strings.Repeat("-", scaleStat(del)))
+	}
+	fmt.Printf(" %d files changed, %d insertions(+), %d deletions(-)\n", len(diffs), additions, deletions)
+}
+
+// scaleStat 把行数压缩到最多 40 个符号
+func scaleStat(n int) int {
+	if n > 40 {
+		return 40
+	}
+	return n
+}
+
+func countLines(hunks []sync.Hunk) (additions, deletions int) {
+	for _, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			switch line.Op {
+			case '+':
+				additions++
+			case '-':
+				deletions++
+			}
+		}
+	}
+	return additions, deletions
+}
+
+func isJSONFile(p string) bool {
+	return strings.EqualFold(path.Ext(p), ".json")
+}
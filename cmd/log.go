@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/sync"
+	gerrors "github.com/geelato/cli/pkg/errors"
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLimit int
+	logJSON  bool
+)
+
+func NewLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show version history(显示云端版本历史)",
+		Long: `List the versions published to the cloud platform, newest first
+
+The version the working tree was last synced with is marked with *.
+Use 'geelato show <version>' to see what a version changed and
+'geelato checkout <version>' to restore the working tree to it.
+
+Example:
+  geelato log
+  geelato log -n 5
+  geelato log --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLog()
+		},
+	}
+
+	cmd.Flags().IntVarP(&logLimit, "limit", "n", 20, "Maximum number of versions to show, 0 for all")
+	cmd.Flags().BoolVar(&logJSON, "json", false, "Output versions as JSON")
+
+	return cmd
+}
+
+func runLog() error {
+	if logJSON {
+		logger.SetLevel(logger.WarnLevel)
+	}
+
+	cwd, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	versions, err := svc.Log(ctx, logLimit)
+	if err != nil {
+		return err
+	}
+
+	if logJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(versions)
+	}
+
+	if len(versions) == 0 {
+		logger.Info("No versions published yet.")
+		return nil
+	}
+
+	state, err := sync.LoadState(cwd)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		marker := " "
+		if v.Version == state.Version {
+			marker = "*"
+		}
+		fmt.Printf("%s %-10s %s  %-12s %s\n", marker, v.Version, formatVersionTime(v.CreatedAt), v.Author, firstLine(v.Message))
+	}
+	return nil
+}
+
+// newAppSyncService 校验当前目录是 Geelato 应用并创建同步服务
+func newAppSyncService() (string, *sync.SyncService, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		logger.Errorf("Failed to get working directory: %v", err)
+		return "", nil, err
+	}
+
+	geelatoPath := filepath.Join(cwd, "geelato.json")
+	if _, err := os.Stat(geelatoPath); os.IsNotExist(err) {
+		logger.Errorf("Not a Geelato application: geelato.json not found in %s", cwd)
+		logger.Info("Please run 'geelato init' first to initialize an application.")
+		return "", nil, gerrors.New(gerrors.ErrAppNotFound, cwd)
+	}
+
+	endpoint, err := app.ResolveEndpoint(cwd)
+	if err != nil {
+		logger.Errorf("Failed to resolve platform endpoint: %v", err)
+		return "", nil, err
+	}
+
+	svc, err := sync.NewSyncService(cwd, endpoint)
+	if err != nil {
+		return "", nil, err
+	}
+	return cwd, svc, nil
+}
+
+func formatVersionTime(t time.Time) string {
+	if t.IsZero() {
+		return "-               "
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/auth"
+	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/internal/platform"
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/geelato/cli/pkg/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loginUsername string
+	loginPassword string
+	loginDevice   bool
+)
+
+func NewLoginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login [server]",
+		Short: "Login to platform(登录平台)",
+		Long: `登录 Geelato 平台并保存访问令牌。
+
+server 可以是平台地址或 profile 名称；省略时使用当前应用的 repo 地址或当前 profile。
+令牌按服务器保存在 ~/.geelato/credentials.json（权限 0600），
+配置 auth.store=encrypted 并设置 GEELATO_CREDENTIALS_PASSPHRASE 后改为加密存储。
+登录后 push、pull、clone 等命令会自动携带令牌。
+
+示例：
+  geelato login http://localhost:8080
+  geelato login prod --username admin
+  geelato login --device`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server := ""
+			if len(args) > 0 {
+				server = args[0]
+			}
+			return runLogin(server)
+		},
+	}
+
+	cmd.Flags().StringVarP(&loginUsername, "username", "u", "", "用户名")
+	cmd.Flags().StringVar(&loginPassword, "password", "", "密码（不建议在命令行中明文传入）")
+	cmd.Flags().BoolVar(&loginDevice, "device", false, "使用设备码登录（在浏览器中确认）")
+
+	return cmd
+}
+
+func NewLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout [server]",
+		Short: "Logout from platform(退出登录)",
+		Long: `吊销平台上的访问令牌并删除本地保存的凭据。
+
+示例：
+  geelato logout
+  geelato logout http://localhost:8080`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server := ""
+			if len(args) > 0 {
+				server = args[0]
+			}
+			return runLogout(server)
+		},
+	}
+}
+
+func runLogin(server string) error {
+	serverURL, err := resolveServer(server)
+	if err != nil {
+		return err
+	}
+
+	store, err := auth.DefaultStore()
+	if err != nil {
+		return err
+	}
+
+	client := platform.NewClientWithEndpoint(config.Get().EndpointFor(serverURL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	var token *auth.Token
+	if loginDevice {
+		token, err = deviceLogin(ctx, client)
+	} else {
+		token, err = passwordLogin(ctx, client)
+	}
+	if err != nil {
+		return fmt.Errorf("登录失败: %w", err)
+	}
+
+	if err := store.Save(token); err != nil {
+		return err
+	}
+
+	logger.Success("已登录 %s (%s)", token.Server, token.Username)
+	return nil
+}
+
+func passwordLogin(ctx context.Context, client *platform.Client) (*auth.Token, error) {
+	username := loginUsername
+	if username == "" {
+		input, err := prompt.Input("Username:")
+		if err != nil {
+			return nil, err
+		}
+		username = strings.TrimSpace(input)
+	}
+	if username == "" {
+		return nil, fmt.Errorf("用户名不能为空")
+	}
+
+	password := loginPassword
+	if password == "" {
+		input, err := prompt.Password("Password:")
+		if err != nil {
+			return nil, err
+		}
+		password = input
+	}
+
+	return client.Login(ctx, username, password)
+}
+
+func deviceLogin(ctx context.Context, client *platform.Client) (*auth.Token, error) {
+	code, err := client.RequestDeviceCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("请在浏览器中打开以下地址并输入授权码完成登录：")
+	logger.Info("  地址:   %s", code.VerificationURI)
+	logger.Info("  授权码: %s", code.UserCode)
+	logger.Info("等待授权...")
+
+	return client.PollDeviceToken(ctx, code)
+}
+
+func runLogout(server string) error {
+	serverURL, err := resolveServer(server)
+	if err != nil {
+		return err
+	}
+
+	store, err := auth.DefaultStore()
+	if err != nil {
+		return err
+	}
+
+	token, err := store.Get(serverURL)
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		logger.Info("未登录 %s", auth.NormalizeServer(serverURL))
+		return nil
+	}
+
+	client := platform.NewClientWithEndpoint(config.Get().EndpointFor(serverURL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := client.Logout(ctx); err != nil {
+		logger.Warn("吊销平台令牌失败，将仅删除本地凭据: %v", err)
+	}
+
+	if err := store.Delete(serverURL); err != nil {
+		return err
+	}
+
+	logger.Success("已退出 %s", token.Server)
+	return nil
+}
+
+// resolveServer 将 profile 名称、平台地址或当前应用解析为服务器地址
+func resolveServer(server string) (string, error) {
+	cfg := config.Get()
+
+	if server != "" {
+		if cfg != nil {
+			if pc, ok := cfg.Profiles[strings.ToLower(server)]; ok {
+				return pc.API.URL, nil
+			}
+		}
+		return auth.NormalizeServer(server), nil
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		if _, err := os.Stat(filepath.Join(cwd, "geelato.json")); err == nil {
+			if ep, err := app.ResolveEndpoint(cwd); err == nil {
+				return ep.URL, nil
+			}
+		}
+	}
+
+	if url := cfg.Endpoint().URL; url != "" {
+		return url, nil
+	}
+
+	return "", fmt.Errorf("请指定服务器地址或 profile 名称")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gerrors "github.com/geelato/cli/pkg/errors"
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+func NewMergeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge <source-branch>",
+		Short: "Merge a branch(将云端分支合并到当前分支)",
+		Long: `Merge another cloud branch into the current branch
+
+The latest version of <source-branch> is merged into the working tree, using
+the last version both branches share as the common ancestor. Changes that
+do not overlap are merged automatically:
+  - entity, page and workflow JSON files key by key, with columns, fields
+    and nodes matched by id; the top-level meta.updatedAt keeps the newer
+    time and meta.version the higher number
+  - API scripts tag by tag in the /** ... */ header, the script body line
+    by line
+
+Real conflicts are recorded for 'geelato sync resolve'. The merge result is
+a local change: review it with 'geelato diff' and publish it with
+'geelato push'. The working tree must be at the latest version of the
+current branch without unpushed changes.
+
+Example:
+  geelato merge feature-order
+  geelato push "Merge branch feature-order"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMerge(args[0])
+		},
+	}
+}
+
+func runMerge(source string) error {
+	_, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	logger.Infof("Merging branch %s into %s...", source, svc.Branch())
+
+	result, err := svc.MergeBranch(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	if result.UpToDate {
+		logger.Infof("Already up to date: %s (version %s) is contained in %s.", result.Source, result.Version, result.Target)
+		return nil
+	}
+	if result.Base == "" {
+		logger.Warnf("Branches %s and %s share no history, merging without a common ancestor.", result.Source, result.Target)
+	}
+
+	for _, path := range result.Updated {
+		logger.Infof("  U %s", path)
+	}
+	for _, path := range result.Merged {
+		logger.Infof("  M %s (merged)", path)
+	}
+	for _, path := range result.Deleted {
+		logger.Infof("  D %s", path)
+	}
+
+	if len(result.Conflicts) > 0 {
+		logger.Warnf("Merged %s (version %s) with %d conflicts:", result.Source, result.Version, len(result.Conflicts))
+		for _, c := range result.Conflicts {
+			logger.Warnf("  C %s", describeConflict(c))
+		}
+		logger.Info("Resolve them with 'geelato sync resolve', then run 'geelato push' to publish the merge.")
+		return gerrors.New(gerrors.ErrSyncConflict, fmt.Sprintf("%d 个文件合并冲突", len(result.Conflicts)))
+	}
+
+	if len(result.Updated)+len(result.Merged)+len(result.Deleted) == 0 {
+		logger.Infof("Nothing to merge from %s.", result.Source)
+		return nil
+	}
+
+	logger.Successf("Merged %s (version %s) into the working tree (%d updated, %d merged, %d deleted)",
+		result.Source, result.Version, len(result.Updated), len(result.Merged), len(result.Deleted))
+	logger.Infof("Review with 'geelato diff', then publish with 'geelato push \"Merge branch %s\"'.", result.Source)
+	return nil
+}
@@ -1,13 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/geelato/cli/cmd/initializer"
+	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/platform"
+	"github.com/geelato/cli/internal/preview"
+	"github.com/geelato/cli/internal/watcher"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/geelato/cli/pkg/prompt"
 	"github.com/spf13/cobra"
@@ -24,6 +35,7 @@ func NewPageCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(pageCreateCmd)
+	cmd.AddCommand(pageServeCmd)
 
 	return cmd
 }
@@ -31,6 +43,10 @@ func NewPageCmd() *cobra.Command {
 var (
 	pageType string
 	pageDesc string
+
+	pageServeHost  string
+	pageServePort  int
+	pageServeLocal bool
 )
 
 var pageCreateCmd = &cobra.Command{
@@ -75,9 +91,152 @@ var pageCreateCmd = &cobra.Command{
 	},
 }
 
+var pageServeCmd = &cobra.Command{
+	Use:   "serve [page-name]",
+	Short: "serve(本地预览页面)",
+	Long: `启动本地页面预览服务，页面文件修改后浏览器自动刷新
+
+页面源码（*.source.json，为空时使用 *.preview.json）通过平台的预览接口渲染，
+平台不可用或指定 --local 时使用本地渲染器显示组件结构。
+页面的 JSON 语法、冲突标记、缺少 componentName 与重复的组件 id 会直接显示在预览页面上。
+
+示例:
+  geelato page serve
+  geelato page serve userList
+  geelato page serve --port 8080 --local`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pageName := ""
+		if len(args) > 0 {
+			pageName = args[0]
+		}
+		return servePages(pageName)
+	},
+}
+
 func init() {
 	pageCreateCmd.Flags().StringVarP(&pageType, "type", "t", "page", "Page type (page, form, dashboard)")
 	pageCreateCmd.Flags().StringVarP(&pageDesc, "desc", "d", "", "Page description")
+
+	pageServeCmd.Flags().StringVar(&pageServeHost, "host", "127.0.0.1", "Address to listen on")
+	pageServeCmd.Flags().IntVarP(&pageServePort, "port", "p", 3300, "Port to listen on")
+	pageServeCmd.Flags().BoolVar(&pageServeLocal, "local", false, "Render pages locally instead of through the platform")
+}
+
+func servePages(pageName string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, "geelato.json")); os.IsNotExist(err) {
+		return fmt.Errorf("current directory is not a valid Geelato application")
+	}
+
+	opts := preview.Options{}
+	if !pageServeLocal {
+		endpoint, err := app.ResolveEndpoint(cwd)
+		if err != nil {
+			logger.Warnf("Failed to resolve platform endpoint, using the local renderer: %v", err)
+		} else {
+			opts.Platform = platform.NewClientWithEndpoint(endpoint)
+			opts.AppID = endpoint.AppCode
+			if appConfig, err := app.LoadAppConfig(cwd); err == nil {
+				if appID := app.GetAppIDFromConfig(appConfig); appID != "" {
+					opts.AppID = appID
+				}
+			}
+		}
+	}
+
+	server := preview.NewServer(cwd, opts)
+	if pageName != "" {
+		if _, err := server.LoadPage(pageName); err != nil {
+			return fmt.Errorf("page '%s' not found in page/", pageName)
+		}
+	}
+
+	addr := net.JoinHostPort(pageServeHost, strconv.Itoa(pageServePort))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	httpServer := &http.Server{Handler: server.Handler()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("Shutting down preview server...")
+		cancel()
+	}()
+
+	watch, err := watcher.NewWatcher(cwd, watcher.Options{})
+	if err != nil {
+		return err
+	}
+	go func() {
+		err := watch.Start(ctx, func(ctx context.Context, paths []string) {
+			for _, page := range changedPages(paths) {
+				if page == "" {
+					logger.Info("Pages changed, reloading")
+				} else {
+					logger.Infof("Page %s changed, reloading", page)
+				}
+				server.Reload(page)
+			}
+		})
+		if err != nil {
+			logger.Warnf("File watcher stopped, pages will not reload automatically: %v", err)
+		}
+	}()
+
+	url := "http://" + listener.Addr().String() + "/"
+	if pageName != "" {
+		url += "page/" + pageName
+	}
+	renderer := "platform"
+	if opts.Platform == nil {
+		renderer = "local"
+	}
+	logger.Infof("Previewing pages at %s (%s renderer). Press Ctrl+C to stop.", url, renderer)
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// changedPages 把变化的路径映射为需要重新加载的页面，空字符串表示全部页面
+func changedPages(paths []string) []string {
+	seen := make(map[string]bool)
+	var pages []string
+	for _, p := range paths {
+		var page string
+		switch {
+		case p == "." || p == "page":
+			page = ""
+		case strings.HasPrefix(p, "page/"):
+			page = strings.SplitN(strings.TrimPrefix(p, "page/"), "/", 2)[0]
+		default:
+			continue
+		}
+		if !seen[page] {
+			seen[page] = true
+			pages = append(pages, page)
+		}
+	}
+	return pages
 }
 
 func createPage(pageName string) error {
@@ -2,29 +2,66 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	cmdsync "github.com/geelato/cli/cmd/sync"
 	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/hook"
 	"github.com/geelato/cli/internal/sync"
+	gerrors "github.com/geelato/cli/pkg/errors"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/geelato/cli/pkg/progress"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pullBranch     string
+	pullAllowDirty bool
+	pullNoVerify   bool
+)
+
 func NewPullCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "pull",
 		Short: "Pull from cloud(从云端拉取最新应用)",
 		Long: `Pull the latest application from cloud platform
 
+Remote changes are merged into local files using the content of the last
+sync (kept under .geelato/base) as the common ancestor. JSON files are merged
+key by key, other text files line by line. Conflicts are written as
+<<<<<<< markers, or for JSON and binary files as <file>.local / <file>.remote
+side files, and recorded in .geelato/conflicts.json for 'geelato sync resolve'.
+
+The latest version of the tracked branch is pulled; --branch merges another
+branch instead and makes the working tree track it.
+
+With git.autoCommit enabled, the application directory must be in a git
+repository without uncommitted changes (--allow-dirty overrides this), and
+every pull that changes files is committed on its own with Geelato-Version /
+Geelato-Branch trailers, so the git history mirrors the platform history.
+A pull with conflicts is not committed.
+
+pre-pull hooks declared in geelato.json (config.hooks) run before the pull
+and abort it on failure; post-pull hooks run after a pull that changed
+files without conflicts. --no-verify skips both.
+
 Example:
-  geelato pull`,
+  geelato pull
+  geelato pull --branch feature-order`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runPull()
 		},
 	}
+
+	cmd.Flags().StringVar(&pullBranch, "branch", "", "Branch to pull from (default: the tracked branch)")
+	cmd.Flags().BoolVar(&pullAllowDirty, "allow-dirty", false, "Pull even if the git working tree has uncommitted changes (git.autoCommit)")
+	cmd.Flags().BoolVar(&pullNoVerify, "no-verify", false, "Skip pre-pull and post-pull hooks")
+
+	return cmd
 }
 
 func runPull() error {
@@ -43,45 +80,54 @@ func runPull() error {
 		return nil
 	}
 
-	// 从 geelato.json 读取 repo 配置
-	appConfig, err := app.LoadAppConfig(cwd)
+	endpoint, err := app.ResolveEndpoint(cwd)
 	if err != nil {
-		logger.Errorf("Failed to load app config: %v", err)
+		logger.Errorf("Failed to resolve platform endpoint: %v", err)
 		return err
 	}
 
-	repoURL := app.GetRepoFromConfig(appConfig)
-	if repoURL == "" {
-		logger.Error("Repo URL not configured. Please run 'geelato config repo <url>' first.")
-		return nil
+	svc, err := sync.NewSyncService(cwd, endpoint)
+	if err != nil {
+		return err
+	}
+	if pullBranch != "" {
+		svc.SetBranch(pullBranch)
 	}
 
-	_, _, apiURL, err := app.ParseRepoURL(repoURL)
+	hooks, err := hook.Load(cwd)
 	if err != nil {
-		logger.Errorf("Failed to parse repo URL: %v", err)
 		return err
 	}
-
-	progressBar := progress.NewBar(100, "Pulling")
-	if progressBar != nil {
-		progressBar.Start()
+	if !pullNoVerify {
+		if err := hooks.Run(hook.PrePull, map[string]string{"BRANCH": svc.Branch()}); err != nil {
+			return err
+		}
 	}
 
-	svc, err := sync.NewSyncService(cwd, apiURL, "")
+	repo, err := cmdsync.OpenAutoCommitRepo(cwd)
 	if err != nil {
-		if progressBar != nil {
-			progressBar.Stop()
-		}
 		return err
 	}
+	var baseVersion string
+	if repo != nil {
+		if err := cmdsync.CheckPullWorktree(repo, pullAllowDirty); err != nil {
+			return err
+		}
+		if state, err := sync.LoadState(cwd); err == nil {
+			baseVersion = state.Version
+		}
+	}
+
+	progressBar := progress.NewBar(100, "Pulling")
 	if progressBar != nil {
+		progressBar.Start()
 		progressBar.Update(30)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	err = svc.Pull(ctx)
+	result, err := svc.Pull(ctx)
 	if err != nil {
 		if progressBar != nil {
 			progressBar.Stop()
@@ -93,6 +139,58 @@ func runPull() error {
 		progressBar.Stop()
 	}
 
+	if len(result.Updated)+len(result.Merged)+len(result.Deleted)+len(result.Conflicts) == 0 {
+		logger.Infof("Already up to date (version %s).", result.Version)
+		return nil
+	}
+
+	for _, path := range result.Updated {
+		logger.Infof("  U %s", path)
+	}
+	for _, path := range result.Merged {
+		logger.Infof("  M %s (merged)", path)
+	}
+	for _, path := range result.Deleted {
+		logger.Infof("  D %s", path)
+	}
+
+	if len(result.Conflicts) > 0 {
+		logger.Warnf("Pulled version %s with %d conflicts:", result.Version, len(result.Conflicts))
+		for _, c := range result.Conflicts {
+			logger.Warnf("  C %s", describeConflict(c))
+		}
+		logger.Info("Resolve them with 'geelato sync resolve' before pushing.")
+		return gerrors.New(gerrors.ErrSyncConflict, fmt.Sprintf("%d 个文件合并冲突", len(result.Conflicts)))
+	}
+
 	logger.Success("Application pulled successfully!")
+	logger.Infof("Branch: %s, version: %s (%d updated, %d merged, %d deleted)", svc.Branch(), result.Version,
+		len(result.Updated), len(result.Merged), len(result.Deleted))
+
+	if repo != nil {
+		message := fmt.Sprintf("Pull platform version %s from branch %s", result.Version, svc.Branch())
+		if err := cmdsync.CommitSync(repo, message, result.Version, svc.Branch(), baseVersion); err != nil {
+			return err
+		}
+	}
+	if !pullNoVerify {
+		return hooks.Run(hook.PostPull, map[string]string{"BRANCH": svc.Branch(), "VERSION": result.Version})
+	}
 	return nil
 }
+
+func describeConflict(c sync.Conflict) string {
+	switch c.Kind {
+	case sync.ConflictDeletedLocal:
+		return fmt.Sprintf("%s (deleted locally, modified remotely; see %s)", c.Path, c.RemoteFile())
+	case sync.ConflictDeletedRemote:
+		return fmt.Sprintf("%s (modified locally, deleted remotely)", c.Path)
+	}
+	if c.LocalInPlace {
+		return fmt.Sprintf("%s (modified on both sides; local file kept, remote version in %s)", c.Path, c.RemoteFile())
+	}
+	if c.Markers {
+		return fmt.Sprintf("%s (conflict markers at line %s)", c.Path, strings.Join(c.Locations, ", "))
+	}
+	return fmt.Sprintf("%s (%s; see %s and %s)", c.Path, strings.Join(c.Locations, ", "), c.LocalFile(), c.RemoteFile())
+}
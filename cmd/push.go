@@ -2,25 +2,66 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	cmdsync "github.com/geelato/cli/cmd/sync"
 	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/hook"
+	"github.com/geelato/cli/internal/platform"
 	"github.com/geelato/cli/internal/sync"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/geelato/cli/pkg/progress"
+	"github.com/geelato/cli/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pushForce      bool
+	pushBranch     string
+	pushAllowDirty bool
+	pushNoVerify   bool
+	pushDryRun     bool
+)
+
 func NewPushCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "push [message]",
 		Short: "Push to cloud(推送变更到云端)",
 		Long: `Push the current application to cloud platform
 
+Only files added, modified or deleted since the last sync (tracked in
+.geelato/sync-state.json) are uploaded.
+
+Before uploading, the changes are checked against the platform using the
+last synced version as the base. If someone else changed the same files on
+the platform, push lists the conflicts and exits with code 3; pull and merge
+first, or use --force to overwrite the platform changes.
+
+Changes are pushed to the branch recorded in .geelato/sync-state.json
+(main by default); --branch pushes to another branch and makes the working
+tree track it.
+
+With git.autoCommit enabled, the application directory must be in a git
+repository. Push refuses to run while it has unstaged changes (--allow-dirty
+overrides this), and after a successful push commits the directory with
+the push message and Geelato-Version / Geelato-Branch trailers.
+
+pre-push hooks declared in geelato.json (config.hooks) run before anything
+is uploaded; a failing hook aborts the push with exit code 4. post-push
+hooks run after a successful push. --no-verify skips both.
+
+--dry-run prints exactly what would be sent without contacting the platform:
+every file with its change, detected type, size and hash, the total upload
+size, and the directories and files that were skipped with the reason.
+
 Example:
   geelato push "feat: add new model"
+  geelato push --dry-run
+  geelato push --branch feature-order
+  geelato push --force
   geelato push`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -28,12 +69,20 @@ Example:
 			if len(args) > 0 {
 				message = args[0]
 			}
-			return runPush(message)
+			return runPush(message, pushForce)
 		},
 	}
+
+	cmd.Flags().BoolVar(&pushForce, "force", false, "Skip conflict check and overwrite platform changes")
+	cmd.Flags().StringVar(&pushBranch, "branch", "", "Branch to push to (default: the tracked branch)")
+	cmd.Flags().BoolVar(&pushAllowDirty, "allow-dirty", false, "Push even if the git working tree has unstaged changes (git.autoCommit)")
+	cmd.Flags().BoolVar(&pushNoVerify, "no-verify", false, "Skip pre-push and post-push hooks")
+	cmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "List the files that would be pushed without sending anything")
+
+	return cmd
 }
 
-func runPush(message string) error {
+func runPush(message string, force bool) error {
 	logger.Info("Preparing to push application to cloud...")
 
 	cwd, err := os.Getwd()
@@ -49,24 +98,46 @@ func runPush(message string) error {
 		return nil
 	}
 
-	// 从 geelato.json 读取 repo 配置
-	appConfig, err := app.LoadAppConfig(cwd)
+	endpoint, err := app.ResolveEndpoint(cwd)
 	if err != nil {
-		logger.Errorf("Failed to load app config: %v", err)
+		logger.Errorf("Failed to resolve platform endpoint: %v", err)
 		return err
 	}
 
-	repoURL := app.GetRepoFromConfig(appConfig)
-	if repoURL == "" {
-		logger.Error("Repo URL not configured. Please run 'geelato config repo <url>' first.")
-		return nil
+	svc, err := sync.NewSyncService(cwd, endpoint)
+	if err != nil {
+		return err
+	}
+	if pushBranch != "" {
+		svc.SetBranch(pushBranch)
+	}
+	if pushDryRun {
+		return runPushDryRun(cwd, svc)
+	}
+
+	hooks, err := hook.Load(cwd)
+	if err != nil {
+		return err
+	}
+	if !pushNoVerify {
+		if err := hooks.Run(hook.PrePush, map[string]string{"BRANCH": svc.Branch()}); err != nil {
+			return err
+		}
 	}
 
-	_, _, apiURL, err := app.ParseRepoURL(repoURL)
+	repo, err := cmdsync.OpenAutoCommitRepo(cwd)
 	if err != nil {
-		logger.Errorf("Failed to parse repo URL: %v", err)
 		return err
 	}
+	var baseVersion string
+	if repo != nil {
+		if err := cmdsync.CheckPushWorktree(repo, pushAllowDirty); err != nil {
+			return err
+		}
+		if state, err := sync.LoadState(cwd); err == nil {
+			baseVersion = state.Version
+		}
+	}
 
 	if message == "" {
 		message = "Update application via CLI"
@@ -75,27 +146,24 @@ func runPush(message string) error {
 	progressBar := progress.NewBar(100, "Pushing")
 	if progressBar != nil {
 		progressBar.Start()
-	}
-
-	svc, err := sync.NewSyncService(cwd, apiURL, "")
-	if err != nil {
-		if progressBar != nil {
-			progressBar.Stop()
-		}
-		return err
-	}
-	if progressBar != nil {
 		progressBar.Update(30)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	err = svc.Push(ctx, message)
+	if force {
+		logger.Warn("--force specified, platform changes to the same files will be overwritten")
+	}
+
+	result, err := svc.Push(ctx, message, force)
 	if err != nil {
 		if progressBar != nil {
 			progressBar.Stop()
 		}
+		if result != nil && len(result.Conflicts) > 0 {
+			displayPushConflicts(result.Conflicts)
+		}
 		return err
 	}
 	if progressBar != nil {
@@ -103,6 +171,86 @@ func runPush(message string) error {
 		progressBar.Stop()
 	}
 
+	if len(result.Changes) == 0 {
+		logger.Info("Nothing to push, application is up to date.")
+		return nil
+	}
+
+	counts := make(map[sync.ChangeType]int)
+	for _, change := range result.Changes {
+		counts[change.Type]++
+	}
+
 	logger.Success("Application pushed successfully!")
+	logger.Infof("Branch: %s, version: %s (%d added, %d modified, %d deleted)", svc.Branch(), result.Version,
+		counts[sync.ChangeAdded], counts[sync.ChangeModified], counts[sync.ChangeDeleted])
+
+	if repo != nil {
+		if err := cmdsync.CommitSync(repo, message, result.Version, svc.Branch(), baseVersion); err != nil {
+			return err
+		}
+	}
+	if !pushNoVerify {
+		return hooks.Run(hook.PostPush, map[string]string{"BRANCH": svc.Branch(), "VERSION": result.Version})
+	}
+	return nil
+}
+
+func runPushDryRun(cwd string, svc *sync.SyncService) error {
+	plan, err := svc.PlanPush()
+	if err != nil {
+		return err
+	}
+
+	base := plan.BaseVersion
+	if base == "" {
+		base = "none, full upload"
+	}
+	fmt.Printf("Dry run: push to branch %s (base version %s), nothing will be sent.\n\n", plan.Branch, base)
+
+	if len(plan.Files) == 0 {
+		fmt.Println("Nothing to push, application is up to date.")
+	} else {
+		fmt.Printf("Files to push (%d):\n", len(plan.Files))
+		deleted := 0
+		for _, f := range plan.Files {
+			size := utils.FormatSize(f.Size)
+			if f.Action == sync.ChangeDeleted {
+				deleted++
+				size = "-"
+			}
+			fmt.Printf("  %s  %-8s  %9s  %-8s  %s\n", changeLetter(f.Action), f.Type, size, shortHash(f.Hash), f.Path)
+		}
+		fmt.Printf("\nTotal: %d files to upload, %d to delete, %s\n", len(plan.Files)-deleted, deleted, utils.FormatSize(plan.Size))
+	}
+
+	fmt.Printf("\nSkipped (%d):\n", len(plan.Skipped))
+	for _, f := range plan.Skipped {
+		fmt.Printf("  %-40s  %s\n", f.Path, f.Reason)
+	}
+	fmt.Printf("  %d files unchanged since the last sync\n", plan.Unchanged)
+
+	if conflicts, err := sync.LoadConflicts(cwd); err == nil && len(conflicts) > 0 {
+		fmt.Println()
+		logger.Warnf("%d unresolved conflicts: the push would be refused until 'geelato sync resolve' is run.", len(conflicts))
+	}
 	return nil
 }
+
+func displayPushConflicts(conflicts []platform.ConflictInfo) {
+	logger.Errorf("Push rejected: %d conflicting files", len(conflicts))
+	for _, c := range conflicts {
+		logger.Errorf("  ! %s (local %s, remote %s)", c.Path, shortHash(c.LocalHash), shortHash(c.RemoteHash))
+	}
+	logger.Info("Run 'geelato pull' to merge the platform changes, or 'geelato push --force' to overwrite them.")
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	if hash == "" {
+		return "-"
+	}
+	return hash
+}
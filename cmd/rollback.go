@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geelato/cli/internal/sync"
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/geelato/cli/pkg/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackMessage string
+	rollbackYes     bool
+)
+
+func NewRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback <version>",
+		Short: "Rollback to a version(将云端回滚到指定版本)",
+		Long: `Re-publish a previous cloud version as a new version
+
+The content of <version> is compared with the latest version and the diff
+is shown for review. After confirmation it is published as a new version
+whose message records the rollback ("Rollback to v12 (from v15)"), so the
+history stays auditable. Local files are not changed; run 'geelato pull'
+afterwards to update them.
+
+Example:
+  geelato rollback v12
+  geelato rollback v12 -m "revert broken order API"
+  geelato rollback v12 --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRollback(args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&rollbackMessage, "message", "m", "", "Reason recorded in the version message")
+	cmd.Flags().BoolVarP(&rollbackYes, "yes", "y", false, "Publish without asking for confirmation")
+
+	return cmd
+}
+
+func runRollback(version string) error {
+	_, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	planCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	plan, err := svc.PlanRollback(planCtx, version)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Changes) == 0 {
+		logger.Infof("Version %s has the same content as the latest version %s, nothing to roll back.", plan.To, plan.From)
+		return nil
+	}
+
+	counts := make(map[sync.ChangeType]int)
+	for _, change := range plan.Changes {
+		counts[change.Type]++
+		oldName := fmt.Sprintf("%s/%s", plan.From, change.Path)
+		newName := fmt.Sprintf("%s/%s", plan.To, change.Path)
+		switch change.Type {
+		case sync.ChangeAdded:
+			oldName = "/dev/null"
+		case sync.ChangeDeleted:
+			newName = "/dev/null"
+		}
+		printContentDiff(change.Path, oldName, newName, change.Old, change.New)
+	}
+	fmt.Println()
+	logger.Infof("Rolling back %s to %s: %d added, %d modified, %d deleted", plan.From, plan.To,
+		counts[sync.ChangeAdded], counts[sync.ChangeModified], counts[sync.ChangeDeleted])
+
+	if !rollbackYes {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Publish %s as a new version?", plan.To), false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			logger.Info("Rollback cancelled.")
+			return nil
+		}
+	}
+
+	// 等待确认的时间不计入发布的超时
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	newVersion, err := svc.Rollback(ctx, plan, rollbackMessage)
+	if err != nil {
+		return err
+	}
+
+	logger.Successf("Rolled back to %s as new version %s", plan.To, newVersion)
+	logger.Info("Run 'geelato pull' to update local files.")
+	return nil
+}
@@ -13,12 +13,16 @@ import (
 )
 
 var (
-	cfgFile  string
-	verbose  bool
-	jsonLogs bool
-	version  = "dev"
-	commit   = "unknown"
-	date     = "unknown"
+	cfgFile    string
+	verbose    bool
+	jsonLogs   bool
+	apiURL     string
+	apiKey     string
+	apiTimeout int
+	profile    string
+	version    = "dev"
+	commit     = "unknown"
+	date       = "unknown"
 )
 
 var rootCmd = &cobra.Command{
@@ -38,8 +42,17 @@ var rootCmd = &cobra.Command{
   geelato pull        - 从云端拉取最新应用
   geelato push        - 推送变更到云端
   geelato diff        - 显示本地与云端的差异
+  geelato log         - 显示云端版本历史
+  geelato show        - 显示云端版本的变更
+  geelato checkout    - 将工作区恢复为云端指定版本
+  geelato rollback    - 将云端回滚到指定版本
+  geelato branch      - 管理云端分支
+  geelato merge       - 将云端分支合并到当前分支
+  geelato watch       - 监听文件变更并自动推送
   geelato validate    - 验证应用配置
   geelato config     - 配置管理
+  geelato login      - 登录平台
+  geelato logout     - 退出登录
   geelato mcp        - MCP平台能力管理
 
 使用 "geelato [command] --help" 查看命令帮助。`,
@@ -52,7 +65,10 @@ var rootCmd = &cobra.Command{
 			logger.SetFormatter(logger.NewJSONFormatter("2006-01-02T15:04:05Z07:00", false))
 		}
 
-		cfg, err := config.Load(cfgFile)
+		cfg, err := config.LoadWithOptions(config.LoadOptions{
+			ConfigPath: cfgFile,
+			Flags:      flagOverrides(cmd),
+		})
 		if err != nil {
 			logger.Warn("加载配置失败: %v", err)
 		}
@@ -78,20 +94,34 @@ func Execute() error {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "配置文件路径")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "显示详细日志")
 	rootCmd.PersistentFlags().BoolVar(&jsonLogs, "json", false, "使用 JSON 格式输出日志")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "使用指定的服务器 profile")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "平台 API 地址（覆盖配置 api.url）")
+	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "平台 API 密钥（覆盖配置 api.key）")
+	rootCmd.PersistentFlags().IntVar(&apiTimeout, "api-timeout", 0, "请求超时秒数（覆盖配置 api.timeout）")
 	rootCmd.Flags().Bool("version", false, "显示版本信息")
 
 	rootCmd.AddCommand(
 		apiCmd,
+		branchCmd,
+		checkoutCmd,
 		cloneCmd,
 		configCmd,
 		diffCmd,
 		initCmd,
+		logCmd,
+		loginCmd,
+		logoutCmd,
 		mcpCmd,
+		mergeCmd,
 		modelCmd,
 		pageCmd,
 		pullCmd,
 		pushCmd,
+		rollbackCmd,
+		showCmd,
+		syncCmd,
 		validateCmd,
+		watchCmd,
 		workflowCmd,
 	)
 
@@ -124,6 +154,25 @@ func Execute() error {
 	return nil
 }
 
+// flagOverrides 收集显式指定的配置类参数，作为优先级最高的配置层
+func flagOverrides(cmd *cobra.Command) map[string]interface{} {
+	overrides := make(map[string]interface{})
+	flags := cmd.Flags()
+	if flags.Changed("profile") {
+		overrides["profile"] = profile
+	}
+	if flags.Changed("api-url") {
+		overrides["api.url"] = apiURL
+	}
+	if flags.Changed("api-key") {
+		overrides["api.key"] = apiKey
+	}
+	if flags.Changed("api-timeout") {
+		overrides["api.timeout"] = apiTimeout
+	}
+	return overrides
+}
+
 func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/geelato/cli/internal/model"
+	"github.com/geelato/cli/internal/sync"
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var showJSON bool
+
+func NewShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <version>",
+		Short: "Show a version(显示云端版本的变更)",
+		Long: `Show a cloud version and the files it changed
+
+The version is compared with the version published before it. Entity
+metadata changes are summarized semantically, e.g. "column ` + "`email`" + ` length 64→128".
+
+Example:
+  geelato show v12
+  geelato show v12 --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runShow(args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&showJSON, "json", false, "Output the version and its changes as JSON")
+
+	return cmd
+}
+
+// versionChangeView 版本中单个文件变化的输出结构
+type versionChangeView struct {
+	Path    string   `json:"path"`
+	Status  string   `json:"status"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+func runShow(version string) error {
+	logger.SetLevel(logger.WarnLevel)
+
+	_, svc, err := newAppSyncService()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	detail, err := svc.Show(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	views := make([]versionChangeView, 0, len(detail.Changes))
+	for _, c := range detail.Changes {
+		view := versionChangeView{Path: c.Path, Status: string(c.Type)}
+		if model.IsMetaFile(c.Path) {
+			if changes, err := model.DiffMeta(c.Path, c.Old, c.New); err == nil {
+				for _, mc := range changes {
+					view.Changes = append(view.Changes, mc.String())
+				}
+			}
+		}
+		views = append(views, view)
+	}
+
+	if showJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{
+			"version":   detail.Version,
+			"parent":    detail.Parent,
+			"author":    detail.Author,
+			"message":   detail.Message,
+			"createdAt": detail.CreatedAt,
+			"files":     views,
+		})
+	}
+
+	fmt.Printf("version %s\n", detail.Version)
+	if detail.Parent != "" {
+		fmt.Printf("Parent: %s\n", detail.Parent)
+	}
+	if detail.Author != "" {
+		fmt.Printf("Author: %s\n", detail.Author)
+	}
+	if !detail.CreatedAt.IsZero() {
+		fmt.Printf("Date:   %s\n", detail.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if detail.Message != "" {
+		fmt.Println()
+		for _, line := range strings.Split(detail.Message, "\n") {
+			fmt.Println(strings.TrimRight("    "+line, " "))
+		}
+	}
+	fmt.Println()
+
+	if len(views) == 0 {
+		fmt.Println("No file changes.")
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, view := range views {
+		counts[view.Status]++
+		fmt.Printf(" %s %s\n", changeLetter(sync.ChangeType(view.Status)), view.Path)
+		for _, change := range view.Changes {
+			fmt.Printf("     %s\n", change)
+		}
+	}
+	fmt.Printf("\n %d files changed (%d added, %d modified, %d deleted)\n", len(views),
+		counts[string(sync.ChangeAdded)], counts[string(sync.ChangeModified)], counts[string(sync.ChangeDeleted)])
+	return nil
+}
+
+func changeLetter(t sync.ChangeType) string {
+	switch t {
+	case sync.ChangeAdded:
+		return "A"
+	case sync.ChangeDeleted:
+		return "D"
+	}
+	return "M"
+}
@@ -0,0 +1,69 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/geelato/cli/internal/model"
+	isync "github.com/geelato/cli/internal/sync"
+)
+
+var (
+	diffHeader = color.New(color.Bold)
+	diffHunk   = color.New(color.FgCyan)
+	diffAdd    = color.New(color.FgGreen)
+	diffDelete = color.New(color.FgRed)
+	diffModify = color.New(color.FgYellow)
+)
+
+// PrintUnifiedDiff 以统一格式输出带颜色的行级差异，终端不支持颜色时自动输出纯文本
+func PrintUnifiedDiff(w io.Writer, oldName, newName string, hunks []isync.Hunk) {
+	diffHeader.Fprintf(w, "--- %s\n", oldName)
+	diffHeader.Fprintf(w, "+++ %s\n", newName)
+	for _, hunk := range hunks {
+		diffHunk.Fprintln(w, hunk.Header())
+		for _, line := range hunk.Lines {
+			switch line.Op {
+			case '-':
+				diffDelete.Fprintf(w, "-%s\n", line.Text)
+			case '+':
+				diffAdd.Fprintf(w, "+%s\n", line.Text)
+			default:
+				fmt.Fprintf(w, " %s\n", line.Text)
+			}
+		}
+	}
+}
+
+// PrintJSONDiff 按键输出 JSON 元数据的语义差异
+func PrintJSONDiff(w io.Writer, oldName, newName string, changes []isync.JSONChange) {
+	diffHeader.Fprintf(w, "--- %s\n", oldName)
+	diffHeader.Fprintf(w, "+++ %s\n", newName)
+	for _, change := range changes {
+		switch change.Op {
+		case '+':
+			diffAdd.Fprintln(w, change.String())
+		case '-':
+			diffDelete.Fprintln(w, change.String())
+		default:
+			diffModify.Fprintln(w, change.String())
+		}
+	}
+}
+
+// PrintMetaDiff 输出实体元数据的语义差异，如字段长度、外键的增删
+func PrintMetaDiff(w io.Writer, oldName, newName string, changes []model.MetaChange) {
+	diffHeader.Fprintf(w, "--- %s\n", oldName)
+	diffHeader.Fprintf(w, "+++ %s\n", newName)
+	for _, change := range changes {
+		switch change.Action {
+		case "added":
+			diffAdd.Fprintf(w, "+ %s\n", change)
+		case "removed":
+			diffDelete.Fprintf(w, "- %s\n", change)
+		default:
+			diffModify.Fprintf(w, "~ %s\n", change)
+		}
+	}
+}
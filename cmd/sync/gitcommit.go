@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/internal/git"
+	gerrors "github.com/geelato/cli/pkg/errors"
+	"github.com/geelato/cli/pkg/logger"
+)
+
+// OpenAutoCommitRepo 开启 git.autoCommit 时打开应用目录所在的 Git 仓库，未开启时返回 nil
+func OpenAutoCommitRepo(cwd string) (*git.Repo, error) {
+	cfg := config.Get()
+	if cfg == nil || !cfg.Git.AutoCommit {
+		return nil, nil
+	}
+
+	repo, err := git.Open(cwd)
+	if err != nil {
+		return nil, err
+	}
+	repo.User = cfg.Git.User
+	repo.Email = cfg.Git.Email
+	return repo, nil
+}
+
+// CheckPushWorktree 推送前要求应用目录没有未暂存的修改：暂存区即本次要发布并提交的内容
+func CheckPushWorktree(repo *git.Repo, allowDirty bool) error {
+	status, err := repo.Status()
+	if err != nil {
+		return err
+	}
+	if len(status.Unstaged) == 0 || allowDirty {
+		return nil
+	}
+
+	logger.Errorf("Git working tree has %d unstaged changes:", len(status.Unstaged))
+	printGitPaths(status.Unstaged)
+	logger.Info("Stage them with 'git add', or use --allow-dirty to push and commit them anyway.")
+	return gerrors.New(gerrors.ErrGit, fmt.Sprintf("%d 个文件有未暂存的修改", len(status.Unstaged)))
+}
+
+// CheckPullWorktree 拉取前要求应用目录没有未提交的修改，使拉取结果单独成为一次提交
+func CheckPullWorktree(repo *git.Repo, allowDirty bool) error {
+	status, err := repo.Status()
+	if err != nil {
+		return err
+	}
+	if status.Clean() || allowDirty {
+		return nil
+	}
+
+	paths := append(append([]string{}, status.Staged...), status.Unstaged...)
+	logger.Errorf("Git working tree has uncommitted changes:")
+	printGitPaths(paths)
+	logger.Info("Commit or stash them first so the pull lands on its own commit, or use --allow-dirty.")
+	return gerrors.New(gerrors.ErrGit, "存在未提交的修改")
+}
+
+func printGitPaths(paths []string) {
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			logger.Errorf("  %s", p)
+		}
+	}
+}
+
+// CommitSync 提交 push/pull 后的应用目录，平台版本与分支写入提交信息的 trailer
+func CommitSync(repo *git.Repo, message, version, branch, base string) error {
+	hash, err := repo.CommitAll(message, [][2]string{
+		{git.TrailerVersion, version},
+		{git.TrailerBranch, branch},
+		{git.TrailerBase, base},
+	})
+	if err != nil {
+		return err
+	}
+	if hash == "" {
+		logger.Info("Nothing to commit in git.")
+		return nil
+	}
+	logger.Successf("Committed %s: %s", hash, commitSubject(message))
+	return nil
+}
+
+func commitSubject(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return subject
+}
@@ -1,34 +1,44 @@
 package sync
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/hook"
+	isync "github.com/geelato/cli/internal/sync"
+	gerrors "github.com/geelato/cli/pkg/errors"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
 var (
-	pullVersion   string
-	pullAutoMerge bool
+	pullVersion    string
+	pullBranch     string
+	pullAllowDirty bool
+	pullNoVerify   bool
 )
 
 var syncPullCmd = &cobra.Command{
-	Use:   "pull",
+	Use:   "pull [version]",
 	Short: "从云端拉取变更",
-	Long: `从云端平台拉取最新的变更到本地。
+	Long: `从云端平台拉取变更，并与本地修改三方合并。
 
-拉取内容包括：
-  - 模型定义的更新
-  - API 接口的变更
-  - 工作流的调整
-  - 配置文件的更新
+只有一侧修改的文件直接采用该侧内容，两侧都修改的文件自动合并，
+无法自动合并的冲突记录下来，使用 "geelato sync resolve" 解决。
+
+与 geelato pull 相同：从当前跟踪的分支拉取（--branch 合并其他分支并改为跟踪该分支），
+开启 git.autoCommit 时要求应用目录没有未提交的修改（--allow-dirty 跳过检查），
+没有冲突的拉取单独提交一次，并运行 geelato.json 中声明的 pre-pull、post-pull 钩子（--no-verify 跳过）。
 
 示例：
   geelato sync pull
-  geelato sync pull --version latest`,
+  geelato sync pull --version v12
+  geelato sync pull --branch feature-order`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
 			pullVersion = args[0]
@@ -40,7 +50,9 @@ var syncPullCmd = &cobra.Command{
 
 func init() {
 	syncPullCmd.Flags().StringVar(&pullVersion, "version", "latest", "指定版本")
-	syncPullCmd.Flags().BoolVar(&pullAutoMerge, "auto-merge", false, "自动合并冲突")
+	syncPullCmd.Flags().StringVar(&pullBranch, "branch", "", "拉取的分支（默认当前跟踪的分支）")
+	syncPullCmd.Flags().BoolVar(&pullAllowDirty, "allow-dirty", false, "git.autoCommit 开启时允许存在未提交的修改")
+	syncPullCmd.Flags().BoolVar(&pullNoVerify, "no-verify", false, "跳过 pre-pull 与 post-pull 钩子")
 }
 
 func runPull() error {
@@ -56,35 +68,80 @@ func runPull() error {
 		return fmt.Errorf("not a Geelato application: geelato.json not found in %s", cwd)
 	}
 
-	// 从 geelato.json 读取 repo 配置
-	appConfig, err := app.LoadAppConfig(cwd)
+	endpoint, err := app.ResolveEndpoint(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve platform endpoint: %w", err)
+	}
+
+	logger.Infof("使用 API URL: %s", endpoint.URL)
+
+	svc, err := isync.NewSyncService(cwd, endpoint)
 	if err != nil {
-		return fmt.Errorf("failed to load app config: %w", err)
+		return err
+	}
+	if pullBranch != "" {
+		svc.SetBranch(pullBranch)
 	}
 
-	repoURL := app.GetRepoFromConfig(appConfig)
-	if repoURL == "" {
-		return fmt.Errorf("repo URL not configured. Please run 'geelato config repo <url>' first")
+	version := pullVersion
+	if version == "latest" {
+		version = ""
 	}
 
-	_, _, apiURL, err := app.ParseRepoURL(repoURL)
+	hooks, err := hook.Load(cwd)
 	if err != nil {
-		return fmt.Errorf("failed to parse repo URL: %w", err)
+		return err
+	}
+	if !pullNoVerify {
+		if err := hooks.Run(hook.PrePull, map[string]string{"BRANCH": svc.Branch()}); err != nil {
+			return err
+		}
 	}
 
-	logger.Infof("使用 API URL: %s", apiURL)
+	repo, err := OpenAutoCommitRepo(cwd)
+	if err != nil {
+		return err
+	}
+	var baseVersion string
+	if repo != nil {
+		if err := CheckPullWorktree(repo, pullAllowDirty); err != nil {
+			return err
+		}
+		if state, err := isync.LoadState(cwd); err == nil {
+			baseVersion = state.Version
+		}
+	}
 
-	manager := NewManagerWithAPI(apiURL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
 
-	status, err := manager.GetStatus()
+	result, err := svc.PullVersion(ctx, version)
 	if err != nil {
-		return fmt.Errorf("获取状态失败: %w", err)
+		return fmt.Errorf("拉取失败: %w", err)
 	}
 
-	logger.Infof("当前版本: %s", status.LocalVersion)
-	logger.Infof("最新版本: %s", status.RemoteVersion)
+	logger.Infof("分支: %s，版本: %s", svc.Branch(), result.Version)
+	logger.Infof("更新 %d 个，合并 %d 个，删除 %d 个文件", len(result.Updated), len(result.Merged), len(result.Deleted))
 
-	logger.Info("拉取功能正在开发中...")
+	if len(result.Conflicts) > 0 {
+		displayConflicts(result.Conflicts)
+		logger.Warn("存在 %d 个冲突，请使用 'geelato sync resolve' 解决", len(result.Conflicts))
+		return gerrors.New(gerrors.ErrSyncConflict, fmt.Sprintf("%d 个文件合并冲突", len(result.Conflicts)))
+	}
 
-	return nil
+	logger.Success("拉取完成")
+	if len(result.Updated)+len(result.Merged)+len(result.Deleted) == 0 {
+		return nil
+	}
+
+	if repo != nil {
+		message := fmt.Sprintf("Pull platform version %s from branch %s", result.Version, svc.Branch())
+		if err := CommitSync(repo, message, result.Version, svc.Branch(), baseVersion); err != nil {
+			return err
+		}
+	}
+	if pullNoVerify {
+		return nil
+	}
+	return hooks.Run(hook.PostPull, map[string]string{"BRANCH": svc.Branch(), "VERSION": result.Version})
 }
@@ -1,21 +1,27 @@
 package sync
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/hook"
+	isync "github.com/geelato/cli/internal/sync"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/geelato/cli/pkg/prompt"
 	"github.com/spf13/cobra"
 )
 
 var (
-	pushMessage string
-	pushAll     bool
-	pushDryRun  bool
+	pushMessage    string
+	pushAll        bool
+	pushDryRun     bool
+	pushBranch     string
+	pushAllowDirty bool
+	pushNoVerify   bool
 )
 
 var syncPushCmd = &cobra.Command{
@@ -29,8 +35,13 @@ var syncPushCmd = &cobra.Command{
   - 新增或修改的工作流
   - 配置文件的变更
 
+与 geelato push 相同：推送到当前跟踪的分支（--branch 指定其他分支），
+推送前检查平台冲突，开启 git.autoCommit 时推送后提交应用目录，
+并运行 geelato.json 中声明的 pre-push、post-push 钩子（--no-verify 跳过）。
+
 示例：
   geelato sync push "更新用户模型"
+  geelato sync push --branch feature-order
   geelato sync push --all`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
@@ -49,6 +60,9 @@ func init() {
 	syncPushCmd.Flags().StringVar(&pushMessage, "message", "", "提交消息")
 	syncPushCmd.Flags().BoolVar(&pushAll, "all", false, "推送所有变更")
 	syncPushCmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "演练模式（不实际推送）")
+	syncPushCmd.Flags().StringVar(&pushBranch, "branch", "", "推送到的分支（默认当前跟踪的分支）")
+	syncPushCmd.Flags().BoolVar(&pushAllowDirty, "allow-dirty", false, "git.autoCommit 开启时允许存在未暂存的修改")
+	syncPushCmd.Flags().BoolVar(&pushNoVerify, "no-verify", false, "跳过 pre-push 与 post-push 钩子")
 }
 
 func runPush() error {
@@ -64,27 +78,22 @@ func runPush() error {
 		return fmt.Errorf("not a Geelato application: geelato.json not found in %s", cwd)
 	}
 
-	// 从 geelato.json 读取 repo 配置
-	appConfig, err := app.LoadAppConfig(cwd)
+	endpoint, err := app.ResolveEndpoint(cwd)
 	if err != nil {
-		return fmt.Errorf("failed to load app config: %w", err)
+		return fmt.Errorf("failed to resolve platform endpoint: %w", err)
 	}
 
-	repoURL := app.GetRepoFromConfig(appConfig)
-	if repoURL == "" {
-		return fmt.Errorf("repo URL not configured. Please run 'geelato config repo <url>' first")
-	}
+	logger.Infof("使用 API URL: %s", endpoint.URL)
 
-	_, _, apiURL, err := app.ParseRepoURL(repoURL)
+	svc, err := isync.NewSyncService(cwd, endpoint)
 	if err != nil {
-		return fmt.Errorf("failed to parse repo URL: %w", err)
+		return err
+	}
+	if pushBranch != "" {
+		svc.SetBranch(pushBranch)
 	}
 
-	logger.Infof("使用 API URL: %s", apiURL)
-
-	manager := NewManagerWithAPI(apiURL)
-
-	changes, err := manager.DetectChanges(".")
+	changes, err := NewManager().DetectChanges(cwd)
 	if err != nil {
 		return fmt.Errorf("检测变更失败: %w", err)
 	}
@@ -94,7 +103,7 @@ func runPush() error {
 		return nil
 	}
 
-	logger.Infof("发现 %d 个变更", len(changes))
+	logger.Infof("发现 %d 个变更，目标分支: %s", len(changes), svc.Branch())
 
 	displayChanges(changes)
 
@@ -114,16 +123,57 @@ func runPush() error {
 		}
 	}
 
-	result, err := manager.Push(changes, pushMessage)
+	hooks, err := hook.Load(cwd)
 	if err != nil {
+		return err
+	}
+	if !pushNoVerify {
+		if err := hooks.Run(hook.PrePush, map[string]string{"BRANCH": svc.Branch()}); err != nil {
+			return err
+		}
+	}
+
+	repo, err := OpenAutoCommitRepo(cwd)
+	if err != nil {
+		return err
+	}
+	var baseVersion string
+	if repo != nil {
+		if err := CheckPushWorktree(repo, pushAllowDirty); err != nil {
+			return err
+		}
+		if state, err := isync.LoadState(cwd); err == nil {
+			baseVersion = state.Version
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	result, err := svc.Push(ctx, pushMessage, false)
+	if err != nil {
+		if result != nil && len(result.Conflicts) > 0 {
+			for _, c := range result.Conflicts {
+				logger.Errorf("  ! %s", c.Path)
+			}
+			logger.Info("请先运行 'geelato sync pull' 合并平台上的修改")
+		}
 		return fmt.Errorf("推送失败: %w", err)
 	}
 
 	logger.Success("推送完成")
-	logger.Infof("版本: %s", result.Version)
-	logger.Infof("变更数量: %d", len(changes))
+	logger.Infof("分支: %s，版本: %s", svc.Branch(), result.Version)
+	logger.Infof("变更数量: %d", len(result.Changes))
 
-	return nil
+	if repo != nil {
+		if err := CommitSync(repo, pushMessage, result.Version, svc.Branch(), baseVersion); err != nil {
+			return err
+		}
+	}
+	if pushNoVerify {
+		return nil
+	}
+	return hooks.Run(hook.PostPush, map[string]string{"BRANCH": svc.Branch(), "VERSION": result.Version})
 }
 
 func displayChanges(changes []Change) {
@@ -134,16 +184,16 @@ func displayChanges(changes []Change) {
 	for _, change := range changes {
 		var icon string
 		switch change.Type {
-		case string(ChangeTypeAdded):
+		case ChangeTypeAdded:
 			icon = "[新增]"
-		case string(ChangeTypeModified):
+		case ChangeTypeModified:
 			icon = "[修改]"
-		case string(ChangeTypeDeleted):
+		case ChangeTypeDeleted:
 			icon = "[删除]"
 		default:
 			icon = "[未知]"
 		}
-		logger.Infof("  %s %s (%s)", icon, change.Path, change.Description)
+		logger.Infof("  %s %s", icon, change.Path)
 	}
 }
 
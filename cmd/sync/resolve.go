@@ -2,39 +2,54 @@ package sync
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 
-	"github.com/spf13/cobra"
+	isync "github.com/geelato/cli/internal/sync"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/geelato/cli/pkg/prompt"
+	"github.com/geelato/cli/pkg/utils"
+	"github.com/spf13/cobra"
 )
 
 var (
 	resolveStrategy string
-	resolveAll     bool
+	resolveAll      bool
 )
 
 var syncResolveCmd = &cobra.Command{
-	Use:   "resolve [file]",
+	Use:   "resolve [file...]",
 	Short: "解决同步冲突",
-	Long: `解决同步过程中检测到的冲突。
+	Long: `解决 pull 合并时记录的冲突（.geelato/conflicts.json）。
+
+逐个展示冲突文件的差异（JSON 元数据按键展示），并选择处理方式：
+  - 保留本地：冲突块采用本地内容；.local/.remote 旁路文件形式的冲突整个文件采用本地版本
+  - 保留云端：冲突块采用云端内容；旁路文件形式的冲突整个文件采用云端版本
+  - 编辑：在 $EDITOR 中打开合并结果，保存后校验不再含冲突标记
+  - 跳过：暂不处理
+
+全部解决后即可重新 push。
 
 冲突解决策略：
-  - ours: 保留本地版本
-  - theirs: 保留云端版本
-  - manual: 手动合并
+  - manual: 交互式逐个处理（默认）
+  - ours: 全部保留本地版本
+  - theirs: 全部保留云端版本
 
 示例：
   geelato sync resolve
-  geelato sync resolve --strategy theirs
-  geelato sync resolve models/user.yaml`,
-	Args: cobra.MaximumNArgs(1),
+  geelato sync resolve --all --strategy theirs
+  geelato sync resolve api/user/saveUser.api.js`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runResolve(args)
 	},
 }
 
 func init() {
-	syncResolveCmd.Flags().StringVar(&resolveStrategy, "strategy", "manual", "解决策略")
+	syncResolveCmd.Flags().StringVar(&resolveStrategy, "strategy", "manual", "解决策略 (manual, ours, theirs)")
 	syncResolveCmd.Flags().BoolVar(&resolveAll, "all", false, "解决所有冲突")
 }
 
@@ -45,6 +60,10 @@ func runResolve(args []string) error {
 	if err != nil {
 		return fmt.Errorf("检测冲突失败: %w", err)
 	}
+	conflicts, err = filterConflicts(conflicts, args)
+	if err != nil {
+		return err
+	}
 
 	if len(conflicts) == 0 {
 		logger.Success("没有检测到冲突")
@@ -53,23 +72,197 @@ func runResolve(args []string) error {
 
 	logger.Warnf("检测到 %d 个冲突", len(conflicts))
 
-	displayConflicts(conflicts)
+	switch resolveStrategy {
+	case "manual":
+		return resolveInteractive(manager, conflicts)
+	case "ours", "theirs":
+		if len(args) == 0 && !resolveAll {
+			displayConflicts(conflicts)
+			return fmt.Errorf("使用 --strategy %s 时请指定文件或加上 --all", resolveStrategy)
+		}
+		for _, conflict := range conflicts {
+			if resolveStrategy == "ours" {
+				err = manager.ResolveWithLocal(conflict)
+			} else {
+				err = manager.ResolveWithRemote(conflict)
+			}
+			if err != nil {
+				return fmt.Errorf("解决冲突 %s 失败: %w", conflict.Path, err)
+			}
+			logger.Infof("  已解决 %s (%s)", conflict.Path, resolveStrategy)
+		}
+		return reportRemaining(manager)
+	default:
+		return fmt.Errorf("未知的解决策略: %s，可选 manual、ours、theirs", resolveStrategy)
+	}
+}
 
-	logger.Info("")
-	logger.Info("冲突解决功能正在开发中...")
-	logger.Info("请手动编辑冲突文件，然后使用 'geelato sync push' 重新推送")
+// filterConflicts 只保留命令行指定的文件
+func filterConflicts(conflicts []Conflict, args []string) ([]Conflict, error) {
+	if len(args) == 0 {
+		return conflicts, nil
+	}
+
+	wanted := make(map[string]bool, len(args))
+	for _, arg := range args {
+		wanted[path.Clean(filepath.ToSlash(arg))] = true
+	}
+
+	var selected []Conflict
+	for _, conflict := range conflicts {
+		if wanted[conflict.Path] {
+			selected = append(selected, conflict)
+			delete(wanted, conflict.Path)
+		}
+	}
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for p := range wanted {
+			missing = append(missing, p)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%s 没有未解决的冲突", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+const (
+	choiceLocal  = "local"
+	choiceRemote = "remote"
+	choiceEdit   = "edit"
+	choiceSkip   = "skip"
+)
+
+func resolveInteractive(manager *Manager, conflicts []Conflict) error {
+	options := []prompt.SelectOption{
+		{Name: "保留本地", Value: choiceLocal},
+		{Name: "保留云端", Value: choiceRemote},
+		{Name: "在编辑器中合并", Value: choiceEdit},
+		{Name: "跳过", Value: choiceSkip},
+	}
+
+	for i, conflict := range conflicts {
+		for {
+			logger.Info("")
+			logger.Infof("[%d/%d] %s", i+1, len(conflicts), describeConflict(conflict))
+			showConflictDiff(conflict)
+
+			choice, err := prompt.Select(fmt.Sprintf("如何处理 %s?", conflict.Path), options)
+			if err != nil {
+				return err
+			}
+
+			switch choice {
+			case choiceLocal:
+				err = manager.ResolveWithLocal(conflict)
+			case choiceRemote:
+				err = manager.ResolveWithRemote(conflict)
+			case choiceEdit:
+				if err = editConflict(conflict); err == nil {
+					if err = manager.ResolveEdited(conflict); err != nil {
+						logger.Warnf("%v，请继续编辑或选择其他处理方式", err)
+						continue
+					}
+				}
+			case choiceSkip:
+				logger.Infof("已跳过 %s", conflict.Path)
+			}
+			if err != nil {
+				return fmt.Errorf("解决冲突 %s 失败: %w", conflict.Path, err)
+			}
+			if choice != choiceSkip {
+				logger.Successf("已解决 %s", conflict.Path)
+			}
+			break
+		}
+	}
+
+	return reportRemaining(manager)
+}
+
+func reportRemaining(manager *Manager) error {
+	remaining, err := manager.DetectConflicts()
+	if err != nil {
+		return err
+	}
 
+	logger.Info("")
+	if len(remaining) > 0 {
+		logger.Warnf("还有 %d 个冲突未解决", len(remaining))
+		return nil
+	}
+	logger.Success("冲突已全部解决，可以运行 'geelato push' 推送合并结果")
 	return nil
 }
 
+func describeConflict(conflict Conflict) string {
+	switch conflict.Kind {
+	case isync.ConflictDeletedLocal:
+		return conflict.Path + "（本地已删除，云端有修改）"
+	case isync.ConflictDeletedRemote:
+		return conflict.Path + "（本地有修改，云端已删除）"
+	}
+	if len(conflict.Locations) > 0 {
+		return fmt.Sprintf("%s（冲突位置: %s）", conflict.Path, strings.Join(conflict.Locations, ", "))
+	}
+	return conflict.Path
+}
+
+// showConflictDiff 展示本地到云端的差异，JSON 元数据按键展示
+func showConflictDiff(conflict Conflict) {
+	local, remote, err := conflict.Sides(".")
+	if err != nil {
+		logger.Warnf("读取冲突内容失败: %v", err)
+		return
+	}
+
+	remoteName := "remote"
+	if conflict.RemoteVersion != "" {
+		remoteName += " (" + conflict.RemoteVersion + ")"
+	}
+
+	if local != nil && remote != nil && strings.EqualFold(path.Ext(conflict.Path), ".json") {
+		if changes, err := isync.DiffJSON(local, remote); err == nil {
+			PrintJSONDiff(os.Stdout, "local", remoteName, changes)
+			return
+		}
+	}
+	PrintUnifiedDiff(os.Stdout, "local", remoteName, isync.UnifiedDiff(local, remote, 3))
+}
+
+// editConflict 在 $EDITOR 中打开合并结果。本地已删除时以云端版本作为编辑起点
+func editConflict(conflict Conflict) error {
+	file := filepath.FromSlash(conflict.Path)
+	if conflict.Kind == isync.ConflictDeletedLocal && !utils.Exists(file) {
+		if err := utils.CopyFile(file+isync.RemoteSuffix, file); err != nil {
+			return err
+		}
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+
+	editCmd := exec.Command(parts[0], append(parts[1:], file)...)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+
+	return editCmd.Run()
+}
+
 func displayConflicts(conflicts []Conflict) {
 	logger.Info("")
 	logger.Info("冲突列表:")
 	logger.Info("---------")
 
 	for i, conflict := range conflicts {
-		logger.Infof("%d. %s", i+1, conflict.Path)
-		logger.Infof("   本地哈希: %s", conflict.LocalHash[:8])
-		logger.Infof("   云端哈希: %s", conflict.RemoteHash[:8])
+		logger.Infof("%d. %s", i+1, describeConflict(conflict))
+		if conflict.Kind == isync.ConflictContent && !conflict.Markers {
+			logger.Infof("   本地版本: %s", conflict.LocalFile())
+			logger.Infof("   云端版本: %s", conflict.RemoteFile())
+		}
 	}
 }
@@ -3,8 +3,8 @@ package sync
 import (
 	"fmt"
 
-	"github.com/spf13/cobra"
 	"github.com/geelato/cli/pkg/logger"
+	"github.com/spf13/cobra"
 )
 
 var (
@@ -48,7 +48,8 @@ func runStatus() error {
 		return printJSON(status)
 	}
 
-	return printStatus(status)
+	printStatus(status)
+	return nil
 }
 
 func printStatus(status *SyncStatusView) {
@@ -2,7 +2,6 @@ package sync
 
 import (
 	"github.com/spf13/cobra"
-	"github.com/geelato/cli/pkg/logger"
 )
 
 var SyncCmd = &cobra.Command{
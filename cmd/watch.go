@@ -5,26 +5,73 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"syscall"
+	"time"
 
+	"github.com/geelato/cli/internal/app"
 	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/internal/sync"
 	"github.com/geelato/cli/internal/watcher"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
+var (
+	watchDebounce       time.Duration
+	watchInterval       time.Duration
+	watchPoll           bool
+	watchBidirectional  bool
+	watchRemoteInterval time.Duration
+)
+
+// watchMessage watch 自动推送的版本说明
+const watchMessage = "Auto sync from geelato watch"
+
 func NewWatchCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "watch",
 		Short: "Watch for changes(监听文件变更并自动同步)",
-		Long: `Watch for file changes and auto-sync to cloud
+		Long: `Watch for file changes and push them to the dev platform
+
+File system notifications (inotify on Linux) are used to detect changes.
+When they are unavailable, for example when the inotify watch limit is
+reached or on network file systems, watch falls back to polling file sizes
+and modification times every --interval (sync.interval seconds by default).
+--poll forces polling.
+
+Bursts of edits are merged: after the last change, watch waits --debounce
+and then pushes only the changed files to the tracked branch, using the same
+conflict check as push. Paths ignored by .geelatoignore are not watched.
+A failed push is retried together with the next change; conflicts must be
+resolved with 'geelato pull' first. Hooks and git.autoCommit are not run
+by watch.
+
+--bidirectional also follows the branch on the platform, for example when
+colleagues edit in the web designer. watch subscribes to the platform's change
+notifications, or checks the branch version every --remote-interval when they
+are unavailable. Remote changes to files you have not modified locally are
+applied; files changed on both sides are never merged or overwritten. They
+are recorded as conflicts with the remote content in <file>.remote, and
+pushing pauses until 'geelato sync resolve' settles them.
 
 Example:
-  geelato watch`,
+  geelato watch
+  geelato watch --bidirectional
+  geelato watch --debounce 1s
+  geelato watch --poll --interval 5s`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runWatch()
 		},
 	}
+
+	cmd.Flags().DurationVar(&watchDebounce, "debounce", watcher.DefaultDebounce, "Wait this long after the last change before pushing")
+	cmd.Flags().DurationVar(&watchInterval, "interval", 0, "Polling interval (default: sync.interval seconds)")
+	cmd.Flags().BoolVar(&watchPoll, "poll", false, "Poll for changes instead of using file system notifications")
+	cmd.Flags().BoolVar(&watchBidirectional, "bidirectional", false, "Also apply changes published on the platform to the local tree")
+	cmd.Flags().DurationVar(&watchRemoteInterval, "remote-interval", 10*time.Second, "How often to check the platform when change notifications are unavailable")
+
+	return cmd
 }
 
 func runWatch() error {
@@ -43,22 +90,40 @@ func runWatch() error {
 		return nil
 	}
 
-	cfg := config.Get()
-	if cfg == nil {
-		logger.Error("Configuration not loaded. Please run 'geelato config set api.url <url>' first.")
-		return nil
+	endpoint, err := app.ResolveEndpoint(cwd)
+	if err != nil {
+		logger.Errorf("Failed to resolve platform endpoint: %v", err)
+		return err
+	}
+
+	svc, err := sync.NewSyncService(cwd, endpoint)
+	if err != nil {
+		return err
+	}
+
+	interval := watchInterval
+	if cfg := config.Get(); interval <= 0 && cfg != nil && cfg.Sync.Interval > 0 {
+		interval = time.Duration(cfg.Sync.Interval) * time.Second
 	}
 
-	watch, err := watcher.NewWatcher(cwd)
+	watch, err := watcher.NewWatcher(cwd, watcher.Options{
+		Debounce: watchDebounce,
+		Interval: interval,
+		Poll:     watchPoll,
+	})
 	if err != nil {
 		return err
 	}
 
+	if state, err := sync.LoadState(cwd); err == nil {
+		if changes, err := sync.DetectChanges(cwd, state); err == nil && len(changes) > 0 {
+			logger.Warnf("%d local changes since the last sync are not pushed yet; run 'geelato push' to push them now.", len(changes))
+		}
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	logger.Info("Watching for file changes. Press Ctrl+C to stop.")
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -68,11 +133,125 @@ func runWatch() error {
 		cancel()
 	}()
 
-	err = watch.Start(ctx)
+	logger.Infof("Pushing changes to branch %s. Press Ctrl+C to stop.", svc.Branch())
+	if watchBidirectional {
+		logger.Infof("Applying remote changes from branch %s to files not modified locally.", svc.Branch())
+	}
+
+	// 本地推送与远端拉取都在当前 goroutine 中执行，避免同时修改同步状态
+	local := make(chan []string)
+	remote := make(chan struct{}, 1)
+	watchErr := make(chan error, 1)
+
+	go func() {
+		watchErr <- watch.Start(ctx, func(ctx context.Context, paths []string) {
+			select {
+			case local <- paths:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	if watchBidirectional {
+		remote <- struct{}{}
+		go svc.WatchRemote(ctx, watchRemoteInterval, func() {
+			select {
+			case remote <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	// retry 推送失败的路径，与下一批变化去重后一起重新推送
+	retry := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Watcher stopped.")
+			return nil
+		case err := <-watchErr:
+			if err != nil {
+				return err
+			}
+		case paths := <-local:
+			failed := pushWatched(ctx, svc, addPaths(retry, paths))
+			retry = make(map[string]bool, len(failed))
+			addPaths(retry, failed)
+		case <-remote:
+			pullWatched(ctx, svc)
+		}
+	}
+}
+
+// addPaths 把 paths 加入集合 set，返回排序后的全部路径
+func addPaths(set map[string]bool, paths []string) []string {
+	for _, p := range paths {
+		set[p] = true
+	}
+	all := make([]string, 0, len(set))
+	for p := range set {
+		all = append(all, p)
+	}
+	sort.Strings(all)
+	return all
+}
+
+// pushWatched 推送变化的路径，失败时返回需要重试的路径
+func pushWatched(ctx context.Context, svc *sync.SyncService, paths []string) []string {
+	pushCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	result, err := svc.PushPaths(pushCtx, watchMessage, paths)
 	if err != nil {
-		return err
+		if ctx.Err() != nil {
+			return nil
+		}
+		if result != nil && len(result.Conflicts) > 0 {
+			displayPushConflicts(result.Conflicts)
+		}
+		logger.Errorf("Push failed: %v", err)
+		logger.Info("The changes will be pushed again with the next change.")
+		return paths
+	}
+	if len(result.Changes) == 0 {
+		return nil
 	}
 
-	logger.Info("Watcher stopped.")
+	for _, change := range result.Changes {
+		logger.Infof("  %s %s", changeLetter(change.Type), change.Path)
+	}
+	logger.Successf("Pushed %d changes to branch %s, version %s", len(result.Changes), svc.Branch(), result.Version)
 	return nil
 }
+
+// pullWatched 把平台上的新版本应用到本地未修改的文件，两侧都修改的文件记为冲突
+func pullWatched(ctx context.Context, svc *sync.SyncService) {
+	pullCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	result, err := svc.PullRemote(pullCtx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Errorf("Failed to apply remote changes: %v", err)
+		}
+		return
+	}
+	if result == nil {
+		return
+	}
+
+	for _, path := range result.Updated {
+		logger.Infof("  U %s", path)
+	}
+	for _, path := range result.Deleted {
+		logger.Infof("  D %s", path)
+	}
+	logger.Successf("Applied remote version %s (%d updated, %d deleted)", result.Version, len(result.Updated), len(result.Deleted))
+
+	if len(result.Conflicts) > 0 {
+		logger.Warnf("%d files changed both locally and remotely were left untouched:", len(result.Conflicts))
+		for _, c := range result.Conflicts {
+			logger.Warnf("  C %s", describeConflict(c))
+		}
+		logger.Info("Resolve them with 'geelato sync resolve'; pushing is paused until then.")
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"time"
 
 	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/internal/hook"
 	"github.com/geelato/cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
-var deployForce bool
+var (
+	deployForce    bool
+	deployNoVerify bool
+)
 
 var workflowDeployCmd = &cobra.Command{
 	Use:   "deploy [name]",
@@ -21,6 +25,9 @@ var workflowDeployCmd = &cobra.Command{
 
 部署前会验证工作流定义，然后将工作流文件上传到云端。
 
+geelato.json 的 config.hooks 中声明的 pre-deploy 钩子在部署前执行，失败时中止部署；
+post-deploy 钩子在全部工作流部署成功后执行。使用 --no-verify 跳过钩子。
+
 示例：
   geelato workflow deploy
   geelato workflow deploy approval
@@ -37,6 +44,7 @@ var workflowDeployCmd = &cobra.Command{
 
 func init() {
 	workflowDeployCmd.Flags().BoolVar(&deployForce, "force", false, "强制部署")
+	workflowDeployCmd.Flags().BoolVar(&deployNoVerify, "no-verify", false, "跳过 pre-deploy 与 post-deploy 钩子")
 }
 
 func runDeploy(name string) error {
@@ -83,6 +91,17 @@ func runDeploy(name string) error {
 	logger.Infof("找到 %d 个工作流", len(workflows))
 	logger.Info("")
 
+	hooks, err := hook.Load(cwd)
+	if err != nil {
+		return err
+	}
+	env := map[string]string{"WORKFLOW": name}
+	if !deployNoVerify {
+		if err := hooks.Run(hook.PreDeploy, env); err != nil {
+			return err
+		}
+	}
+
 	deployed := 0
 	failed := 0
 
@@ -108,6 +127,9 @@ func runDeploy(name string) error {
 	logger.Info("")
 	logger.Infof("部署完成: %d 成功, %d 失败", deployed, failed)
 
+	if failed == 0 && !deployNoVerify {
+		return hooks.Run(hook.PostDeploy, env)
+	}
 	return nil
 }
 
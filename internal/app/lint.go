@@ -0,0 +1,275 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/geelato/cli/internal/file"
+)
+
+// LintIssue 静态检查发现的问题，Line 为 0 表示针对整个文件
+type LintIssue struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.Path, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// lintDirs 参与静态检查的目录
+var lintDirs = []string{"meta", "api", "page", "workflow"}
+
+var (
+	conflictMarker = regexp.MustCompile(`^(<{7}|>{7})( |$)`)
+	apiTag         = regexp.MustCompile(`^\s*\*\s*@(\w+)\s*(.*)$`)
+)
+
+// Lint 对应用文件做静态检查，跳过 .geelatoignore 忽略的文件：JSON 语法、残留的冲突标记、API 脚本文件头的 @name 与 @path、
+// 重复的 API 路径、字段定义中重复的 id 与 fieldName 以及页面组件树的 componentName 与重复 id。结果按路径与行号排序
+func Lint(cwd string) ([]LintIssue, error) {
+	ignore, err := file.LoadIgnore(cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	apiPaths := make(map[string]string)
+
+	for _, dir := range lintDirs {
+		root := filepath.Join(cwd, dir)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(cwd, path)
+			rel = filepath.ToSlash(rel)
+			if ignore.Ignored(rel, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			issues = append(issues, lintFile(rel, data, apiPaths)...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s/: %w", dir, err)
+		}
+	}
+
+	sortIssues(issues)
+	return issues, nil
+}
+
+// LintPage 只检查 page/<name> 目录下的页面文件，规则与 Lint 相同
+func LintPage(cwd, name string) ([]LintIssue, error) {
+	dir := filepath.Join(cwd, "page", name)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		rel := "page/" + name + "/" + entry.Name()
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, lintFile(rel, data, nil)...)
+	}
+
+	sortIssues(issues)
+	return issues, nil
+}
+
+// lintFile 按文件类型检查单个文件，二进制文件跳过；apiPaths 为 nil 时不检查 API 路径重复
+func lintFile(rel string, data []byte, apiPaths map[string]string) []LintIssue {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil
+	}
+	issues := lintConflictMarkers(rel, data)
+
+	switch {
+	case strings.HasSuffix(rel, ".json"):
+		issues = append(issues, lintJSON(rel, data)...)
+	case strings.HasSuffix(rel, ".api.js"):
+		if apiPaths == nil {
+			apiPaths = make(map[string]string)
+		}
+		issues = append(issues, lintAPIScript(rel, data, apiPaths)...)
+	}
+	return issues
+}
+
+func sortIssues(issues []LintIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+}
+
+func lintConflictMarkers(path string, data []byte) []LintIssue {
+	var issues []LintIssue
+	for i, line := range strings.Split(string(data), "\n") {
+		if conflictMarker.MatchString(line) {
+			issues = append(issues, LintIssue{Path: path, Line: i + 1, Message: "unresolved conflict marker"})
+		}
+	}
+	return issues
+}
+
+func lintJSON(path string, data []byte) []LintIssue {
+	// page create 生成的 source/release/preview 文件在设计前是空的
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		issue := LintIssue{Path: path, Message: "invalid JSON: " + err.Error()}
+		if se, ok := err.(*json.SyntaxError); ok {
+			issue.Line = bytes.Count(data[:se.Offset], []byte("\n")) + 1
+		}
+		return []LintIssue{issue}
+	}
+
+	if strings.HasSuffix(path, ".source.json") || strings.HasSuffix(path, ".preview.json") {
+		return lintPageSource(path, doc)
+	}
+	if !strings.HasSuffix(path, ".columns.json") {
+		return nil
+	}
+	obj, _ := doc.(map[string]interface{})
+	columns, _ := obj["columns"].([]interface{})
+
+	var issues []LintIssue
+	ids := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, c := range columns {
+		col, _ := c.(map[string]interface{})
+		if id, _ := col["id"].(string); id != "" {
+			if ids[id] {
+				issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf("duplicate column id %q", id)})
+			}
+			ids[id] = true
+		}
+		if name, _ := col["fieldName"].(string); name != "" {
+			if names[name] {
+				issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf("duplicate fieldName %q", name)})
+			}
+			names[name] = true
+		}
+	}
+	return issues
+}
+
+// lintPageSource 检查页面组件树：children 中的每个节点都要有 componentName，组件 id 不能重复。
+// 问题以 JSON 路径定位，例如 children[0].children[2]
+func lintPageSource(path string, doc interface{}) []LintIssue {
+	var issues []LintIssue
+	ids := make(map[string]string)
+
+	var walk func(node map[string]interface{}, at string)
+	walk = func(node map[string]interface{}, at string) {
+		name, _ := node["componentName"].(string)
+		if name == "" {
+			issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf("component at %s has no componentName", at)})
+		}
+		if id, _ := node["id"].(string); id != "" {
+			if other, ok := ids[id]; ok {
+				issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf("component id %q at %s is also used at %s", id, at, other)})
+			} else {
+				ids[id] = at
+			}
+		}
+
+		children, _ := node["children"].([]interface{})
+		for i, c := range children {
+			child, ok := c.(map[string]interface{})
+			if !ok {
+				issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf("%s.children[%d] is not a component object", at, i)})
+				continue
+			}
+			walk(child, fmt.Sprintf("%s.children[%d]", at, i))
+		}
+	}
+
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return []LintIssue{{Path: path, Message: "page source must be a JSON object"}}
+	}
+	walk(root, "$")
+	return issues
+}
+
+// lintAPIScript 检查 /** ... */ 文件头中的 @name 与 @path，apiPaths 记录已出现的路径用于查重
+func lintAPIScript(path string, data []byte, apiPaths map[string]string) []LintIssue {
+	lines := strings.Split(string(data), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "/**") {
+		return []LintIssue{{Path: path, Line: 1, Message: "missing /** ... */ API header"}}
+	}
+
+	tags := make(map[string]string)
+	tagLines := make(map[string]int)
+	closed := false
+	for i, line := range lines {
+		if m := apiTag.FindStringSubmatch(line); m != nil {
+			tags[m[1]] = strings.TrimSpace(m[2])
+			tagLines[m[1]] = i + 1
+		}
+		if strings.Contains(line, "*/") {
+			closed = true
+			break
+		}
+	}
+	if !closed {
+		return []LintIssue{{Path: path, Line: 1, Message: "API header is not closed with */"}}
+	}
+
+	var issues []LintIssue
+	for _, tag := range []string{"name", "path"} {
+		if tags[tag] == "" {
+			issues = append(issues, LintIssue{Path: path, Line: 1, Message: fmt.Sprintf("API header has no @%s", tag)})
+		}
+	}
+
+	if p := tags["path"]; p != "" {
+		key := strings.ToUpper(tags["method"]) + " " + p
+		if other, ok := apiPaths[key]; ok {
+			issues = append(issues, LintIssue{Path: path, Line: tagLines["path"],
+				Message: fmt.Sprintf("@path %s is also declared in %s", p, other)})
+		} else {
+			apiPaths[key] = path
+		}
+	}
+	return issues
+}
@@ -3,10 +3,8 @@ package app
 import (
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/geelato/cli/internal/config"
 )
@@ -135,35 +133,22 @@ func GetRepoFromConfig(config map[string]interface{}) string {
 	return ""
 }
 
-// ParseRepoURL 解析 repo URL，返回 tenant, appCode, apiURL
-func ParseRepoURL(repoURL string) (tenant, appCode, apiURL string, err error) {
-	repoURL = strings.TrimSpace(repoURL)
-
-	if !strings.HasPrefix(repoURL, "http://") && !strings.HasPrefix(repoURL, "https://") {
-		repoURL = "http://" + repoURL
+// GetAppIDFromConfig 从配置中获取应用 ID（meta.appId）
+func GetAppIDFromConfig(config map[string]interface{}) string {
+	if meta, ok := config["meta"].(map[string]interface{}); ok {
+		if appID, ok := meta["appId"].(string); ok {
+			return appID
+		}
 	}
+	return ""
+}
 
-	u, err := url.Parse(repoURL)
+// ResolveEndpoint 读取应用的 repo 配置，并结合当前 profile 解析平台地址
+func ResolveEndpoint(appPath string) (*config.Endpoint, error) {
+	appConfig, err := LoadAppConfig(appPath)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to parse URL: %w", err)
-	}
-
-	path := strings.TrimPrefix(u.Path, "/")
-	path = strings.TrimSuffix(path, "/")
-
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
-		return "", "", "", fmt.Errorf("URL path should contain tenant and app code (e.g., /tenant/app-code)")
-	}
-
-	tenant = parts[0]
-	appCode = parts[1]
-
-	if tenant == "" || appCode == "" {
-		return "", "", "", fmt.Errorf("tenant and app code cannot be empty")
+		return nil, err
 	}
 
-	apiURL = u.Scheme + "://" + u.Host
-
-	return tenant, appCode, apiURL, nil
+	return config.Get().ResolveEndpoint(GetRepoFromConfig(appConfig))
 }
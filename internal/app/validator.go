@@ -3,7 +3,8 @@ package app
 import (
 	"os"
 	"path/filepath"
-	"strings"
+
+	"github.com/geelato/cli/internal/file"
 )
 
 type ValidationResult struct {
@@ -17,6 +18,7 @@ type ValidationResult struct {
 type Validator struct {
 	cwd    string
 	errors []string
+	ignore *file.Ignore
 }
 
 func NewValidator() *Validator {
@@ -33,6 +35,12 @@ func (v *Validator) Validate(cwd string) (*ValidationResult, error) {
 	v.cwd = cwd
 	v.errors = []string{}
 
+	ignore, err := file.LoadIgnore(cwd)
+	if err != nil {
+		return nil, err
+	}
+	v.ignore = ignore
+
 	result.Models = v.validateDir("meta", []string{".json"})
 	result.APIs = v.validateDir("api", []string{".js"})
 	result.Workflows = v.validateDir("workflow", []string{".xml", ".bpmn"})
@@ -58,6 +66,13 @@ func (v *Validator) validateDir(name string, extensions []string) int {
 		if err != nil {
 			return err
 		}
+		rel, _ := filepath.Rel(v.cwd, path)
+		if v.ignore.Ignored(filepath.ToSlash(rel), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if info.IsDir() {
 			return nil
 		}
@@ -79,23 +94,3 @@ func (v *Validator) validateDir(name string, extensions []string) int {
 
 	return count
 }
-
-func isIgnoredDir(dirName string) bool {
-	ignored := []string{"node_modules", ".git", "vendor", "__pycache__", ".idea", ".vscode"}
-	for _, ig := range ignored {
-		if dirName == ig {
-			return true
-		}
-	}
-	return false
-}
-
-func isIgnoredFile(fileName string) bool {
-	ignoredPrefixes := []string{".", "_"}
-	for _, prefix := range ignoredPrefixes {
-		if strings.HasPrefix(fileName, prefix) {
-			return true
-		}
-	}
-	return false
-}
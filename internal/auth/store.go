@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/pkg/crypto"
+)
+
+const (
+	// StoreFile 明文凭据文件，权限 0600
+	StoreFile = "file"
+	// StoreEncrypted 使用口令加密的凭据文件
+	StoreEncrypted = "encrypted"
+
+	// PassphraseEnv 加密凭据存储的口令环境变量
+	PassphraseEnv = "GEELATO_CREDENTIALS_PASSPHRASE"
+)
+
+// Store 按服务器保存登录凭据
+type Store interface {
+	Get(server string) (*Token, error)
+	Save(token *Token) error
+	Delete(server string) error
+}
+
+// fileStore 将凭据以 JSON 形式保存在单个文件中，可选加密
+type fileStore struct {
+	path string
+	// passphrase 加密口令，为空时明文保存
+	passphrase string
+}
+
+// NewFileStore 创建明文凭据存储
+func NewFileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+// NewEncryptedStore 创建使用口令加密的凭据存储，每次写入时使用新的随机盐派生密钥
+func NewEncryptedStore(path, passphrase string) Store {
+	return &fileStore{path: path, passphrase: passphrase}
+}
+
+// DefaultStore 根据 auth.store 配置返回凭据存储
+func DefaultStore() (Store, error) {
+	dir := credentialsDir()
+
+	storeType := StoreFile
+	if cfg := config.Get(); cfg != nil && cfg.Auth.Store != "" {
+		storeType = cfg.Auth.Store
+	}
+
+	switch storeType {
+	case StoreFile:
+		return NewFileStore(filepath.Join(dir, "credentials.json")), nil
+	case StoreEncrypted:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("使用加密凭据存储需要设置环境变量 %s", PassphraseEnv)
+		}
+		return NewEncryptedStore(filepath.Join(dir, "credentials.enc"), passphrase), nil
+	default:
+		return nil, fmt.Errorf("不支持的凭据存储类型: %s", storeType)
+	}
+}
+
+// LoadToken 从默认存储读取服务器的凭据，不存在或读取失败时返回 nil
+func LoadToken(server string) *Token {
+	store, err := DefaultStore()
+	if err != nil {
+		return nil
+	}
+	token, err := store.Get(server)
+	if err != nil {
+		return nil
+	}
+	return token
+}
+
+func credentialsDir() string {
+	return filepath.Dir(config.GlobalConfigPath())
+}
+
+func (s *fileStore) Get(server string) (*Token, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return tokens[NormalizeServer(server)], nil
+}
+
+func (s *fileStore) Save(token *Token) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	token.Server = NormalizeServer(token.Server)
+	tokens[token.Server] = token
+	return s.write(tokens)
+}
+
+func (s *fileStore) Delete(server string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(tokens, NormalizeServer(server))
+	return s.write(tokens)
+}
+
+func (s *fileStore) load() (map[string]*Token, error) {
+	tokens := make(map[string]*Token)
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取凭据文件失败: %w", err)
+	}
+
+	if s.passphrase != "" {
+		data, err = crypto.DecryptWithPassphrase(s.passphrase, data)
+		if err != nil {
+			return nil, fmt.Errorf("凭据文件解密失败，请检查 %s: %w", PassphraseEnv, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("解析凭据文件失败: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *fileStore) write(tokens map[string]*Token) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if s.passphrase != "" {
+		data, err = crypto.EncryptWithPassphrase(s.passphrase, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("创建凭据目录失败: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("保存凭据失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("保存凭据失败: %w", err)
+	}
+	return os.Chmod(s.path, 0600)
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Token 表示某个平台服务器的登录凭据
+type Token struct {
+	Server       string    `json:"server"`
+	Username     string    `json:"username,omitempty"`
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	TokenType    string    `json:"tokenType,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt,omitempty"`
+}
+
+// Expired 判断访问令牌是否已过期，未设置过期时间时视为长期有效
+func (t *Token) Expired() bool {
+	if t == nil || t.ExpiresAt.IsZero() {
+		return false
+	}
+	return time.Now().After(t.ExpiresAt)
+}
+
+// NormalizeServer 规范化服务器地址，作为凭据存储的键
+func NormalizeServer(server string) string {
+	server = strings.TrimSpace(server)
+	if server == "" {
+		return ""
+	}
+	if !strings.HasPrefix(server, "http://") && !strings.HasPrefix(server, "https://") {
+		server = "http://" + server
+	}
+
+	u, err := url.Parse(server)
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(server, "/")
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
+
+// TokenResponse 平台登录、刷新接口返回的令牌信息
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
+	Username     string `json:"username"`
+	Error        string `json:"error"`
+}
+
+// Token 转换为指定服务器的登录凭据
+func (r *TokenResponse) Token(server string) (*Token, error) {
+	if r.AccessToken == "" {
+		return nil, fmt.Errorf("平台未返回访问令牌")
+	}
+
+	token := &Token{
+		Server:       NormalizeServer(server),
+		Username:     r.Username,
+		AccessToken:  r.AccessToken,
+		RefreshToken: r.RefreshToken,
+		TokenType:    r.TokenType,
+	}
+	if r.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
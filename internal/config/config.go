@@ -1,33 +1,89 @@
 package config
 
+import (
+	"time"
+
+	"github.com/geelato/cli/pkg/retry"
+)
+
 var globalConfig *Config
 
 type Config struct {
-	API    APIConfig
-	Git    GitConfig
-	Sync   SyncConfig
-	MCP    MCPConfig
+	API     APIConfig
+	Git     GitConfig
+	Sync    SyncConfig
+	MCP     MCPConfig
 	Logging LoggingConfig
-	Cache  CacheConfig
+	Cache   CacheConfig
+	Auth    AuthConfig
+
+	// Profile 当前使用的 profile 名称，为空时直接使用 API 配置
+	Profile  string
+	Profiles map[string]ProfileConfig
+
+	sources    map[string]Source
+	settings   []Setting
+	globalFile string
 }
 
 type APIConfig struct {
 	URL     string
 	Key     string
 	Timeout int
+	Retry   RetryConfig
+	// Proxy 代理地址，支持 http://、https://、socks5://，为空时使用 HTTP_PROXY/HTTPS_PROXY 环境变量
+	Proxy string
+	// NoProxy 不经过代理的主机，逗号分隔，支持域名后缀、IP 与 CIDR，为空时使用 NO_PROXY 环境变量
+	NoProxy string
+	TLS     TLSConfig
+}
+
+// TLSConfig 连接平台时的证书配置
+type TLSConfig struct {
+	// CAFile 自定义 CA 证书（PEM），追加到系统根证书之后
+	CAFile string
+	// CertFile、KeyFile 双向 TLS 使用的客户端证书与私钥（PEM）
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify 跳过服务端证书校验，仅限测试环境使用
+	InsecureSkipVerify bool
+}
+
+// RetryConfig 平台请求的重试策略，间隔单位为毫秒
+type RetryConfig struct {
+	MaxAttempts     int
+	InitialInterval int
+	MaxInterval     int
+}
+
+// Policy 转换为重试策略，未配置的字段使用默认值
+func (r RetryConfig) Policy() retry.Policy {
+	policy := retry.DefaultPolicy()
+	if r.MaxAttempts > 0 {
+		policy.MaxAttempts = r.MaxAttempts
+	}
+	if r.InitialInterval > 0 {
+		policy.InitialInterval = time.Duration(r.InitialInterval) * time.Millisecond
+	}
+	if r.MaxInterval > 0 {
+		policy.MaxInterval = time.Duration(r.MaxInterval) * time.Millisecond
+	}
+	return policy
 }
 
 type GitConfig struct {
 	Repository string
-	Branch    string
-	User      string
-	Email     string
+	Branch     string
+	User       string
+	Email      string
+	// AutoCommit push 与 pull 成功后在应用目录所在的 Git 仓库中自动提交
+	AutoCommit bool
 }
 
 type SyncConfig struct {
-	AutoPush  bool
-	AutoPull  bool
-	Interval  int
+	AutoPush bool
+	AutoPull bool
+	Interval int
 }
 
 type MCPConfig struct {
@@ -45,8 +101,22 @@ type CacheConfig struct {
 	Dir string
 }
 
+// AuthConfig 登录凭据的存储方式：file（默认，0600 明文文件）或 encrypted
+type AuthConfig struct {
+	Store string
+}
+
+// Load 按默认值、全局配置、项目配置、环境变量的顺序加载配置
 func Load(configPath string) (*Config, error) {
-	return &Config{}, nil
+	return LoadWithOptions(LoadOptions{ConfigPath: configPath})
+}
+
+// GlobalFile 返回加载时使用的全局配置文件路径
+func (c *Config) GlobalFile() string {
+	if c == nil || c.globalFile == "" {
+		return GlobalConfigPath()
+	}
+	return c.globalFile
 }
 
 func Get() *Config {
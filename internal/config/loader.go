@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Source 表示配置值的来源层级
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceGlobal  Source = "global"
+	SourceProject Source = "project"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// EnvPrefix 环境变量前缀，如 GEELATO_API_URL 对应 api.url
+const EnvPrefix = "GEELATO"
+
+const (
+	globalConfigDir   = ".geelato"
+	globalConfigName  = "geelato.yaml"
+	projectConfigDir  = ".geelato"
+	projectConfigName = "config.yaml"
+)
+
+// LoadOptions 控制配置加载的输入
+type LoadOptions struct {
+	// ConfigPath 指定全局配置文件路径，为空时使用 ~/.geelato/geelato.yaml
+	ConfigPath string
+	// ProjectDir 项目目录，为空时使用当前工作目录
+	ProjectDir string
+	// Flags 命令行参数覆盖的配置项，键为 api.url 形式
+	Flags map[string]interface{}
+}
+
+// Setting 表示一个生效的配置项及其来源
+type Setting struct {
+	Key    string
+	Value  interface{}
+	Source Source
+	File   string
+}
+
+var defaults = map[string]interface{}{
+	"api.url":                    "",
+	"api.key":                    "",
+	"api.timeout":                30,
+	"api.retry.maxattempts":      3,
+	"api.retry.initialinterval":  500,
+	"api.retry.maxinterval":      10000,
+	"api.proxy":                  "",
+	"api.noproxy":                "",
+	"api.tls.cafile":             "",
+	"api.tls.certfile":           "",
+	"api.tls.keyfile":            "",
+	"api.tls.insecureskipverify": false,
+	"git.repository":             "",
+	"git.branch":                 "main",
+	"git.user":                   "",
+	"git.email":                  "",
+	"git.autocommit":             false,
+	"sync.autopush":              false,
+	"sync.autopull":              false,
+	"sync.interval":              2,
+	"mcp.enabled":                false,
+	"mcp.cachedir":               "",
+	"logging.level":              "info",
+	"logging.format":             "text",
+	"logging.output":             "stdout",
+	"cache.dir":                  "",
+	"auth.store":                 "file",
+	"profile":                    "",
+}
+
+// layer 记录单个层级读取到的配置值
+type layer struct {
+	source Source
+	file   string
+	values map[string]interface{}
+}
+
+// LoadWithOptions 按优先级从低到高合并各层配置：
+// 默认值 < 全局配置文件 < 项目配置文件 < GEELATO_* 环境变量 < 命令行参数
+func LoadWithOptions(opts LoadOptions) (*Config, error) {
+	layers := []layer{{source: SourceDefault, values: defaults}}
+
+	var loadErr error
+
+	globalPath, explicit := opts.ConfigPath, opts.ConfigPath != ""
+	if !explicit {
+		globalPath = GlobalConfigPath()
+	}
+	if l, err := readFileLayer(SourceGlobal, globalPath, explicit); err != nil {
+		loadErr = err
+	} else if l != nil {
+		layers = append(layers, *l)
+	}
+
+	projectDir := opts.ProjectDir
+	if projectDir == "" {
+		projectDir, _ = os.Getwd()
+	}
+	if projectDir != "" {
+		projectPath := filepath.Join(projectDir, projectConfigDir, projectConfigName)
+		if l, err := readFileLayer(SourceProject, projectPath, false); err != nil && loadErr == nil {
+			loadErr = err
+		} else if l != nil {
+			layers = append(layers, *l)
+		}
+	}
+
+	layers = append(layers, envLayer(), layer{source: SourceFlag, values: normalizeKeys(opts.Flags)})
+
+	cfg, err := build(layers)
+	cfg.globalFile = globalPath
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, loadErr
+}
+
+// GlobalConfigPath 返回全局配置文件路径
+func GlobalConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(globalConfigDir, globalConfigName)
+	}
+	return filepath.Join(homeDir, globalConfigDir, globalConfigName)
+}
+
+func readFileLayer(source Source, path string, required bool) (*layer, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取配置文件失败 %s: %w", path, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if filepath.Ext(path) == "" {
+		v.SetConfigType("yaml")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败 %s: %w", path, err)
+	}
+
+	values := make(map[string]interface{})
+	for _, key := range v.AllKeys() {
+		values[key] = v.Get(key)
+	}
+
+	return &layer{source: source, file: path, values: values}, nil
+}
+
+func envLayer() layer {
+	values := make(map[string]interface{})
+	for key := range defaults {
+		if value, ok := os.LookupEnv(EnvName(key)); ok {
+			values[key] = value
+		}
+	}
+	return layer{source: SourceEnv, values: values}
+}
+
+// EnvName 返回配置项对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func normalizeKeys(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[strings.ToLower(k)] = v
+	}
+	return result
+}
+
+func build(layers []layer) (*Config, error) {
+	merged := viper.New()
+	sources := make(map[string]Source)
+	files := make(map[string]string)
+
+	for _, l := range layers {
+		for key, value := range l.values {
+			merged.Set(key, value)
+			sources[key] = l.source
+			files[key] = l.file
+		}
+	}
+
+	cfg := &Config{sources: sources}
+	if err := merged.Unmarshal(cfg); err != nil {
+		return cfg, fmt.Errorf("解析配置失败: %w", err)
+	}
+
+	cfg.settings = make([]Setting, 0, len(sources))
+	for key, source := range sources {
+		cfg.settings = append(cfg.settings, Setting{
+			Key:    key,
+			Value:  merged.Get(key),
+			Source: source,
+			File:   files[key],
+		})
+	}
+	sort.Slice(cfg.settings, func(i, j int) bool {
+		return cfg.settings[i].Key < cfg.settings[j].Key
+	})
+
+	return cfg, nil
+}
+
+// Source 返回配置项生效值的来源
+func (c *Config) Source(key string) Source {
+	if c == nil || c.sources == nil {
+		return SourceDefault
+	}
+	if source, ok := c.sources[strings.ToLower(key)]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Settings 返回所有生效的配置项，按键名排序
+func (c *Config) Settings() []Setting {
+	if c == nil {
+		return nil
+	}
+	return c.settings
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileConfig 命名的服务器配置，保存在全局配置文件的 profiles 节点下
+type ProfileConfig struct {
+	API    APIConfig
+	Tenant string
+}
+
+// Profile 表示一个生效的服务器配置
+type Profile struct {
+	Name   string
+	API    APIConfig
+	Tenant string
+}
+
+// Endpoint 表示一次平台调用最终使用的地址与凭据
+type Endpoint struct {
+	Profile string
+	URL     string
+	Key     string
+	Timeout int
+	Retry   RetryConfig
+	Proxy   string
+	NoProxy string
+	TLS     TLSConfig
+	Tenant  string
+	AppCode string
+}
+
+// ProfileNames 返回已配置的 profile 名称，按字母排序
+func (c *Config) ProfileNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile 返回当前生效的 profile。未选择 profile 时返回由 api.* 配置构成的默认 profile
+func (c *Config) ActiveProfile() (*Profile, error) {
+	if c == nil {
+		return &Profile{API: APIConfig{Timeout: defaults["api.timeout"].(int)}}, nil
+	}
+
+	base := &Profile{API: c.API}
+	if c.Profile == "" {
+		return base, nil
+	}
+
+	name := strings.ToLower(c.Profile)
+	pc, ok := c.Profiles[name]
+	if !ok {
+		return base, fmt.Errorf("profile '%s' 不存在，请使用 'geelato config profile add' 创建", c.Profile)
+	}
+
+	return c.namedProfile(name, pc), nil
+}
+
+// namedProfile 构造命名 profile，未配置的超时、重试、代理与证书沿用 api.* 配置
+func (c *Config) namedProfile(name string, pc ProfileConfig) *Profile {
+	p := &Profile{Name: name, API: pc.API, Tenant: pc.Tenant}
+	if p.API.Timeout == 0 {
+		p.API.Timeout = c.API.Timeout
+	}
+	if p.API.Retry == (RetryConfig{}) {
+		p.API.Retry = c.API.Retry
+	}
+	if p.API.Proxy == "" {
+		p.API.Proxy = c.API.Proxy
+	}
+	if p.API.NoProxy == "" {
+		p.API.NoProxy = c.API.NoProxy
+	}
+	if p.API.TLS == (TLSConfig{}) {
+		p.API.TLS = c.API.TLS
+	}
+	return p
+}
+
+// Endpoint 返回当前 profile 对应的平台地址，不涉及应用仓库地址
+func (c *Config) Endpoint() *Endpoint {
+	p, err := c.ActiveProfile()
+	if err != nil {
+		return &Endpoint{Timeout: p.API.Timeout}
+	}
+	return p.endpoint()
+}
+
+// EndpointFor 返回访问指定服务器时使用的配置，不携带 API Key。
+// 服务器属于某个 profile 时使用该 profile 的代理与证书，否则沿用当前 profile 的设置
+func (c *Config) EndpointFor(server string) *Endpoint {
+	server = strings.TrimSuffix(server, "/")
+	if c != nil {
+		for _, name := range c.ProfileNames() {
+			pc := c.Profiles[name]
+			if strings.EqualFold(strings.TrimSuffix(pc.API.URL, "/"), server) {
+				ep := c.namedProfile(name, pc).endpoint()
+				ep.Key = ""
+				return ep
+			}
+		}
+	}
+
+	ep := c.Endpoint()
+	ep.Profile = ""
+	ep.URL = server
+	ep.Key = ""
+	return ep
+}
+
+func (p *Profile) endpoint() *Endpoint {
+	return &Endpoint{
+		Profile: p.Name,
+		URL:     strings.TrimSuffix(p.API.URL, "/"),
+		Key:     p.API.Key,
+		Timeout: p.API.Timeout,
+		Retry:   p.API.Retry,
+		Proxy:   p.API.Proxy,
+		NoProxy: p.API.NoProxy,
+		TLS:     p.API.TLS,
+		Tenant:  p.Tenant,
+	}
+}
+
+// ResolveEndpoint 结合当前 profile 与应用仓库地址解析平台调用地址。
+// 显式选择了 profile 时以 profile 的地址和租户为准，仓库地址只提供应用编码；
+// 未选择 profile 时沿用仓库地址中的服务器地址和租户。
+func (c *Config) ResolveEndpoint(repoURL string) (*Endpoint, error) {
+	p, err := c.ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	ep := c.Endpoint()
+
+	if repoURL != "" {
+		tenant, appCode, apiURL, err := ParseRepoURL(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		ep.AppCode = appCode
+		if p.Name == "" || ep.URL == "" {
+			ep.URL = apiURL
+		}
+		if p.Name == "" || ep.Tenant == "" {
+			ep.Tenant = tenant
+		}
+	}
+
+	if ep.URL == "" {
+		return nil, fmt.Errorf("未配置平台地址，请使用 'geelato config profile add' 或 'geelato config repo <url>' 配置")
+	}
+
+	return ep, nil
+}
+
+// ParseRepoURL 解析 repo URL，返回 tenant, appCode, apiURL
+func ParseRepoURL(repoURL string) (tenant, appCode, apiURL string, err error) {
+	repoURL = strings.TrimSpace(repoURL)
+
+	if !strings.HasPrefix(repoURL, "http://") && !strings.HasPrefix(repoURL, "https://") {
+		repoURL = "http://" + repoURL
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	path := strings.TrimPrefix(u.Path, "/")
+	path = strings.TrimSuffix(path, "/")
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return "", "", "", fmt.Errorf("URL path should contain tenant and app code (e.g., /tenant/app-code)")
+	}
+
+	tenant = parts[0]
+	appCode = parts[1]
+
+	if tenant == "" || appCode == "" {
+		return "", "", "", fmt.Errorf("tenant and app code cannot be empty")
+	}
+
+	apiURL = u.Scheme + "://" + u.Host
+
+	return tenant, appCode, apiURL, nil
+}
+
+// SaveProfile 在全局配置文件中新增或更新 profile
+func SaveProfile(path, name string, profile ProfileConfig) error {
+	doc, err := readYAML(path)
+	if err != nil {
+		return err
+	}
+
+	profiles, _ := doc["profiles"].(map[string]interface{})
+	if profiles == nil {
+		profiles = make(map[string]interface{})
+	}
+
+	api := map[string]interface{}{"url": profile.API.URL}
+	if profile.API.Key != "" {
+		api["key"] = profile.API.Key
+	}
+	if profile.API.Timeout > 0 {
+		api["timeout"] = profile.API.Timeout
+	}
+	if profile.API.Proxy != "" {
+		api["proxy"] = profile.API.Proxy
+	}
+	if profile.API.NoProxy != "" {
+		api["noProxy"] = profile.API.NoProxy
+	}
+	if tls := tlsNode(profile.API.TLS); len(tls) > 0 {
+		api["tls"] = tls
+	}
+	entry := map[string]interface{}{"api": api}
+	if profile.Tenant != "" {
+		entry["tenant"] = profile.Tenant
+	}
+
+	profiles[strings.ToLower(name)] = entry
+	doc["profiles"] = profiles
+
+	return writeYAML(path, doc)
+}
+
+func tlsNode(t TLSConfig) map[string]interface{} {
+	node := make(map[string]interface{})
+	if t.CAFile != "" {
+		node["caFile"] = t.CAFile
+	}
+	if t.CertFile != "" {
+		node["certFile"] = t.CertFile
+	}
+	if t.KeyFile != "" {
+		node["keyFile"] = t.KeyFile
+	}
+	if t.InsecureSkipVerify {
+		node["insecureSkipVerify"] = true
+	}
+	return node
+}
+
+// RemoveProfile 从全局配置文件中删除 profile，若其为当前 profile 则一并清除
+func RemoveProfile(path, name string) error {
+	doc, err := readYAML(path)
+	if err != nil {
+		return err
+	}
+
+	name = strings.ToLower(name)
+	profiles, _ := doc["profiles"].(map[string]interface{})
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("profile '%s' 不存在", name)
+	}
+	delete(profiles, name)
+
+	if current, _ := doc["profile"].(string); strings.ToLower(current) == name {
+		delete(doc, "profile")
+	}
+
+	return writeYAML(path, doc)
+}
+
+// UseProfile 将 profile 设为全局配置文件中的当前 profile
+func UseProfile(path, name string) error {
+	doc, err := readYAML(path)
+	if err != nil {
+		return err
+	}
+
+	name = strings.ToLower(name)
+	profiles, _ := doc["profiles"].(map[string]interface{})
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("profile '%s' 不存在", name)
+	}
+	doc["profile"] = name
+
+	return writeYAML(path, doc)
+}
+
+func readYAML(path string) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return doc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+	return doc, nil
+}
+
+func writeYAML(path string, doc map[string]interface{}) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+	return nil
+}
@@ -1,26 +1,28 @@
 package file
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/internal/transport"
 	"github.com/geelato/cli/pkg/crypto"
 	"github.com/geelato/cli/pkg/logger"
+	"github.com/geelato/cli/pkg/utils"
 )
 
+// uploadTimeout 上传、下载应用包的超时时间
+const uploadTimeout = 5 * time.Minute
+
+// HTTPClient 基于 transport 的文件同步客户端，路径相对于平台地址
 type HTTPClient struct {
-	baseURL string
-	key     string
-	client  *http.Client
+	client *transport.Client
 }
 
 type UploadBody struct {
@@ -28,169 +30,86 @@ type UploadBody struct {
 	Filename string
 }
 
-func NewHTTPClient(baseURL, key string) (*HTTPClient, error) {
-	if baseURL == "" {
+func NewHTTPClient(endpoint *config.Endpoint) (*HTTPClient, error) {
+	if endpoint == nil || endpoint.URL == "" {
 		return nil, fmt.Errorf("API URL cannot be empty")
 	}
 
-	baseURL = strings.TrimSuffix(baseURL, "/")
-
-	return &HTTPClient{
-		baseURL: baseURL,
-		key:     key,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}, nil
+	return &HTTPClient{client: transport.NewWithEndpoint(endpoint)}, nil
 }
 
-func (c *HTTPClient) request(method, path string, body interface{}) ([]byte, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
-
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
-		}
-		reqBody = bytes.NewBuffer(jsonData)
-	}
-
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if c.key != "" {
-		req.Header.Set("X-API-Key", c.key)
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
+// SetAuthToken 设置请求使用的 Bearer 令牌
+func (c *HTTPClient) SetAuthToken(token string) {
+	c.client.Credentials().SetAccessToken(token)
+}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
+func (c *HTTPClient) request(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	resp, err := c.client.Do(ctx, transport.Request{
+		Method: method,
+		Path:   path,
+		Body:   body,
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return respBody, nil
+	return resp.Body, nil
 }
 
 func (c *HTTPClient) Get(path string) ([]byte, error) {
-	return c.request(http.MethodGet, path, nil)
+	return c.request(context.Background(), http.MethodGet, path, nil)
 }
 
 func (c *HTTPClient) GetWithContext(ctx context.Context, path string) ([]byte, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if c.key != "" {
-		req.Header.Set("X-API-Key", c.key)
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	respBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	return respBody, nil
+	return c.request(ctx, http.MethodGet, path, nil)
 }
 
 func (c *HTTPClient) Post(path string, body interface{}) ([]byte, error) {
-	return c.request(http.MethodPost, path, body)
+	return c.request(context.Background(), http.MethodPost, path, body)
 }
 
 func (c *HTTPClient) Put(path string, body interface{}) ([]byte, error) {
-	return c.request(http.MethodPut, path, body)
+	return c.request(context.Background(), http.MethodPut, path, body)
 }
 
 func (c *HTTPClient) Delete(path string) ([]byte, error) {
-	return c.request(http.MethodDelete, path, nil)
+	return c.request(context.Background(), http.MethodDelete, path, nil)
 }
 
+// Upload 上传文件。请求携带 Idempotency-Key，失败重试时服务端不会重复处理
 func (c *HTTPClient) Upload(ctx context.Context, path string, uploadBody *UploadBody) ([]byte, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, uploadBody.File)
+	key, err := utils.RandomHex(16)
 	if err != nil {
 		return nil, err
 	}
 
-	if c.key != "" {
-		req.Header.Set("X-API-Key", c.key)
-	}
-
-	fileInfo, _ := uploadBody.File.Stat()
-	req.Header.Set("Content-Type", "application/zip")
-	req.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", uploadBody.Filename))
-	req.ContentLength = fileInfo.Size()
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	respBody, err := ioutil.ReadAll(resp.Body)
+	resp, err := c.client.Do(ctx, transport.Request{
+		Method:      http.MethodPost,
+		Path:        path,
+		RawBody:     uploadBody.File,
+		ContentType: "application/zip",
+		Headers: map[string]string{
+			"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", uploadBody.Filename),
+		},
+		IdempotencyKey: key,
+		Timeout:        uploadTimeout,
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return respBody, nil
+	return resp.Body, nil
 }
 
 func (c *HTTPClient) Download(ctx context.Context, path string, writer io.Writer) ([]byte, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if c.key != "" {
-		req.Header.Set("X-API-Key", c.key)
-	}
-
-	resp, err := c.client.Do(req)
+	resp, err := c.client.Stream(ctx, transport.Request{
+		Method:  http.MethodGet,
+		Path:    path,
+		Timeout: uploadTimeout,
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("download failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
 	_, err = io.Copy(writer, resp.Body)
 	if err != nil {
 		return nil, err
@@ -204,12 +123,12 @@ func HashFile(path string) (string, error) {
 	return crypto.HashFile(path, crypto.SHA256)
 }
 
-func WalkDirectory(root string, includePatterns []string, excludePatterns []string) ([]string, error) {
+// WalkDirectory 返回 root 下未被 ignore 忽略、且扩展名匹配 includePatterns 的文件相对路径。
+// ignore 为 nil 时只使用默认规则
+func WalkDirectory(root string, includePatterns []string, ignore *Ignore) ([]string, error) {
 	var files []string
-
-	excludeSet := make(map[string]bool)
-	for _, pattern := range excludePatterns {
-		excludeSet[pattern] = true
+	if ignore == nil {
+		ignore = NewIgnore()
 	}
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -226,8 +145,7 @@ func WalkDirectory(root string, includePatterns []string, excludePatterns []stri
 			return nil
 		}
 
-		dir := filepath.Dir(relPath)
-		if excludeSet[dir] || excludeSet[filepath.Base(dir)] {
+		if ignore.Ignored(filepath.ToSlash(relPath), info.IsDir()) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
@@ -0,0 +1,210 @@
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile 应用根目录下的忽略规则文件，语法与 .gitignore 相同
+const IgnoreFile = ".geelatoignore"
+
+// alwaysIgnored CLI 状态目录与版本库目录，.geelatoignore 中的 ! 规则也不能取消
+var alwaysIgnored = []string{".geelato/", ".git/"}
+
+// defaultIgnored 默认忽略的依赖、缓存与编辑器目录，可以在 .geelatoignore 中用 ! 规则取消
+var defaultIgnored = []string{"node_modules/", "vendor/", "__pycache__/", ".idea/", ".vscode/"}
+
+// IgnoreRule 一条忽略规则。Source 为 default 或 .geelatoignore，Line 为规则所在的行号
+type IgnoreRule struct {
+	Pattern string
+	Source  string
+	Line    int
+
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+func (r *IgnoreRule) String() string {
+	if r.Line > 0 {
+		return fmt.Sprintf("%s:%d %s", r.Source, r.Line, r.Pattern)
+	}
+	return fmt.Sprintf("%s %s", r.Source, r.Pattern)
+}
+
+// Ignore 按 gitignore 语义匹配应用内的相对路径：支持 # 注释、! 取反、以 / 结尾的目录规则、
+// 含 / 的规则相对根目录匹配、*、?、[...] 与 **。后出现的规则优先，目录被忽略时其下的内容都被忽略
+type Ignore struct {
+	always []*IgnoreRule
+	rules  []*IgnoreRule
+}
+
+// NewIgnore 返回只包含默认规则的 Ignore
+func NewIgnore() *Ignore {
+	ig := &Ignore{}
+	for _, p := range alwaysIgnored {
+		ig.always = append(ig.always, compileIgnoreRule(p, "default", 0))
+	}
+	ig.Add("default", defaultIgnored...)
+	return ig
+}
+
+// LoadIgnore 读取 root 下的 .geelatoignore，追加在默认规则之后；文件不存在时只使用默认规则
+func LoadIgnore(root string) (*Ignore, error) {
+	ig := NewIgnore()
+
+	f, err := os.Open(filepath.Join(root, IgnoreFile))
+	if os.IsNotExist(err) {
+		return ig, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if rule := compileIgnoreRule(scanner.Text(), IgnoreFile, line); rule != nil {
+			ig.rules = append(ig.rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+	return ig, nil
+}
+
+// Add 追加规则，source 用于说明规则来源
+func (ig *Ignore) Add(source string, patterns ...string) {
+	for _, p := range patterns {
+		if rule := compileIgnoreRule(p, source, 0); rule != nil {
+			ig.rules = append(ig.rules, rule)
+		}
+	}
+}
+
+// Ignored 判断相对路径（/ 分隔）是否被忽略，任一上级目录被忽略时也视为被忽略
+func (ig *Ignore) Ignored(rel string, isDir bool) bool {
+	return ig.Match(rel, isDir) != nil
+}
+
+// Match 返回使路径被忽略的规则，未被忽略时返回 nil
+func (ig *Ignore) Match(rel string, isDir bool) *IgnoreRule {
+	rel = strings.Trim(filepath.ToSlash(rel), "/")
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if rule := ig.matchOne(strings.Join(parts[:i], "/"), true); rule != nil {
+			return rule
+		}
+	}
+	return ig.matchOne(rel, isDir)
+}
+
+func (ig *Ignore) matchOne(rel string, isDir bool) *IgnoreRule {
+	for _, rule := range ig.always {
+		if rule.matches(rel, isDir) {
+			return rule
+		}
+	}
+	for i := len(ig.rules) - 1; i >= 0; i-- {
+		rule := ig.rules[i]
+		if rule.matches(rel, isDir) {
+			if rule.negate {
+				return nil
+			}
+			return rule
+		}
+	}
+	return nil
+}
+
+func (r *IgnoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	return r.re.MatchString(rel)
+}
+
+// compileIgnoreRule 把一行 gitignore 规则转换为正则，空行与注释返回 nil
+func compileIgnoreRule(line, source string, lineNo int) *IgnoreRule {
+	pattern := strings.TrimRight(line, " \t\r")
+	if strings.HasSuffix(pattern, "\\") && strings.HasSuffix(line, " ") {
+		pattern += " "
+	}
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return nil
+	}
+
+	rule := &IgnoreRule{Pattern: pattern, Source: source, Line: lineNo}
+	switch {
+	case strings.HasPrefix(pattern, "!"):
+		rule.negate = true
+		pattern = pattern[1:]
+	case strings.HasPrefix(pattern, `\!`), strings.HasPrefix(pattern, `\#`):
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return nil
+	}
+
+	// 含 / 的规则相对根目录匹配，否则匹配任意层级的名称
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var re strings.Builder
+	re.WriteString("^")
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			re.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+
+	compiled, err := regexp.Compile(re.String())
+	if err != nil {
+		return nil
+	}
+	rule.re = compiled
+	return rule
+}
@@ -0,0 +1,62 @@
+package file
+
+import "testing"
+
+func TestIgnoreRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		ignored  bool
+	}{
+		// ! 不能重新包含已被忽略目录下的文件
+		{"negate under excluded dir", []string{"build/", "!build/keep.txt"}, "build/keep.txt", false, true},
+		{"negate under excluded dir contents", []string{"build/*", "!build/keep.txt"}, "build/keep.txt", false, false},
+		{"negate leaves siblings ignored", []string{"build/*", "!build/keep.txt"}, "build/other.txt", false, true},
+		{"later rule wins", []string{"!*.log", "*.log"}, "app.log", false, true},
+		{"negate after wildcard", []string{"*.log", "!important.log"}, "logs/important.log", false, false},
+
+		// 以 / 开头的规则只匹配根目录
+		{"leading slash at root", []string{"/config.json"}, "config.json", false, true},
+		{"leading slash not nested", []string{"/config.json"}, "page/config.json", false, false},
+		{"no slash matches any level", []string{"config.json"}, "page/home/config.json", false, true},
+		{"middle slash is anchored", []string{"page/tmp"}, "api/page/tmp", false, false},
+
+		// a/**/b 匹配零个或多个中间目录
+		{"double star zero dirs", []string{"a/**/b"}, "a/b", false, true},
+		{"double star one dir", []string{"a/**/b"}, "a/x/b", false, true},
+		{"double star many dirs", []string{"a/**/b"}, "a/x/y/z/b", false, true},
+		{"double star anchored", []string{"a/**/b"}, "c/a/b", false, false},
+		{"double star whole name", []string{"a/**/b"}, "a/x/bc", false, false},
+		{"leading double star", []string{"**/cache"}, "x/y/cache", true, true},
+		{"trailing double star", []string{"dist/**"}, "dist/js/app.js", false, true},
+
+		// 以 / 结尾的规则只匹配目录
+		{"trailing slash dir", []string{"logs/"}, "logs", true, true},
+		{"trailing slash file", []string{"logs/"}, "logs", false, false},
+		{"trailing slash contents", []string{"logs/"}, "logs/app.txt", false, true},
+		{"trailing slash nested dir", []string{"logs/"}, "api/logs/app.txt", false, true},
+
+		{"character class", []string{"file[0-9].txt"}, "file7.txt", false, true},
+		{"negated character class", []string{"file[!0-9].txt"}, "file7.txt", false, false},
+		{"question mark", []string{"?.tmp"}, "a.tmp", false, true},
+		{"star stays in segment", []string{"page/*.json"}, "page/home/page.json", false, false},
+		{"escaped bang", []string{`\!important`}, "!important", false, true},
+		{"comment", []string{"# page"}, "page", true, false},
+
+		{"default ignored", nil, "node_modules/x/index.js", false, true},
+		{"default re-included", []string{"!node_modules/"}, "node_modules/x/index.js", false, false},
+		{"state dir always ignored", []string{"!.geelato/"}, ".geelato/sync-state.json", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ig := NewIgnore()
+			ig.Add(".geelatoignore", tt.patterns...)
+			if got := ig.Ignored(tt.path, tt.isDir); got != tt.ignored {
+				t.Errorf("patterns %q: Ignored(%q, %v) = %v, want %v", tt.patterns, tt.path, tt.isDir, got, tt.ignored)
+			}
+		})
+	}
+}
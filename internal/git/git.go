@@ -0,0 +1,151 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	gerrors "github.com/geelato/cli/pkg/errors"
+)
+
+// 提交信息中记录平台版本的 trailer 键，可用 git log --format='%(trailers)' 查询
+const (
+	TrailerVersion = "Geelato-Version"
+	TrailerBranch  = "Geelato-Branch"
+	TrailerBase    = "Geelato-Base-Version"
+)
+
+// stateDir CLI 自己维护的目录，不计入工作区修改
+const stateDir = ".geelato/"
+
+// appPathspec 应用目录内除 stateDir 以外的全部文件
+var appPathspec = []string{".", ":(exclude)" + strings.TrimSuffix(stateDir, "/")}
+
+// Repo 应用目录所在的 Git 工作区，所有操作都限定在应用目录内
+type Repo struct {
+	dir string
+	// User、Email 提交作者，为空时使用 git 自身的配置
+	User  string
+	Email string
+}
+
+// Open 打开 dir 所在的 Git 工作区，dir 不在 Git 仓库中时返回 ErrGitNotRepo
+func Open(dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, gerrors.Wrap(err, gerrors.ErrGit, "未找到 git 命令")
+	}
+	r := &Repo{dir: dir}
+	out, err := r.run(nil, "rev-parse", "--is-inside-work-tree")
+	if err != nil || strings.TrimSpace(out) != "true" {
+		return nil, gerrors.New(gerrors.ErrGitNotRepo, fmt.Sprintf("%s 不在 Git 仓库中，请先执行 git init", dir))
+	}
+	return r, nil
+}
+
+// Status 应用目录中的工作区修改
+type Status struct {
+	// Staged 已暂存的文件
+	Staged []string
+	// Unstaged 未暂存的修改与未跟踪的文件
+	Unstaged []string
+}
+
+// Clean 没有任何未提交的修改
+func (s *Status) Clean() bool {
+	return len(s.Staged) == 0 && len(s.Unstaged) == 0
+}
+
+// Status 读取应用目录的工作区状态，路径相对应用目录，忽略 .geelato 下由 CLI 维护的文件
+func (r *Repo) Status() (*Status, error) {
+	prefix, err := r.run(nil, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, gerrors.Wrap(err, gerrors.ErrGit, "读取 Git 状态失败")
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	out, err := r.run(nil, "status", "--porcelain", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, gerrors.Wrap(err, gerrors.ErrGit, "读取 Git 状态失败")
+	}
+
+	status := &Status{}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		x, y := entry[0], entry[1]
+		// 重命名与复制后跟一项原路径
+		if x == 'R' || x == 'C' {
+			i++
+		}
+		path := strings.TrimPrefix(entry[3:], prefix)
+		if strings.HasPrefix(path, stateDir) {
+			continue
+		}
+		if x != ' ' && x != '?' {
+			status.Staged = append(status.Staged, path)
+		}
+		if y != ' ' {
+			status.Unstaged = append(status.Unstaged, path)
+		}
+	}
+	return status, nil
+}
+
+// CommitAll 暂存应用目录中的全部修改并提交，只提交应用目录内的文件，不包含 stateDir。
+// trailers 按给定顺序追加在提交信息末尾。没有可提交的内容时返回空字符串
+func (r *Repo) CommitAll(message string, trailers [][2]string) (string, error) {
+	if _, err := r.run(nil, append([]string{"add", "-A", "--"}, appPathspec...)...); err != nil {
+		return "", gerrors.Wrap(err, gerrors.ErrGitCommit, "暂存文件失败")
+	}
+	if _, err := r.run(nil, append([]string{"diff", "--cached", "--quiet", "--"}, appPathspec...)...); err == nil {
+		return "", nil
+	}
+
+	var msg strings.Builder
+	msg.WriteString(strings.TrimSpace(message))
+	msg.WriteString("\n\n")
+	for _, t := range trailers {
+		if t[1] != "" {
+			fmt.Fprintf(&msg, "%s: %s\n", t[0], t[1])
+		}
+	}
+
+	if _, err := r.run(strings.NewReader(msg.String()), append([]string{"commit", "--quiet", "-F", "-", "--"}, appPathspec...)...); err != nil {
+		return "", gerrors.Wrap(err, gerrors.ErrGitCommit, "创建提交失败")
+	}
+
+	out, err := r.run(nil, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", gerrors.Wrap(err, gerrors.ErrGitCommit, "读取提交失败")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (r *Repo) run(stdin *strings.Reader, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	cmd.Env = os.Environ()
+	if r.User != "" {
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_NAME="+r.User, "GIT_COMMITTER_NAME="+r.User)
+	}
+	if r.Email != "" {
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_EMAIL="+r.Email, "GIT_COMMITTER_EMAIL="+r.Email)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,226 @@
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/geelato/cli/internal/app"
+	gerrors "github.com/geelato/cli/pkg/errors"
+	"github.com/geelato/cli/pkg/logger"
+)
+
+// 支持的钩子事件。pre-* 失败时中止对应的命令，post-* 在命令成功后执行
+const (
+	PrePush    = "pre-push"
+	PostPush   = "post-push"
+	PrePull    = "pre-pull"
+	PostPull   = "post-pull"
+	PreDeploy  = "pre-deploy"
+	PostDeploy = "post-deploy"
+)
+
+var events = map[string]bool{
+	PrePush: true, PostPush: true,
+	PrePull: true, PostPull: true,
+	PreDeploy: true, PostDeploy: true,
+}
+
+// Hook 一个钩子：内置检查或 shell 命令，二者只能设置一个
+type Hook struct {
+	Builtin string `json:"builtin,omitempty"`
+	Run     string `json:"run,omitempty"`
+}
+
+// UnmarshalJSON 支持字符串简写：内置检查的名称表示内置检查，其余表示 shell 命令
+func (h *Hook) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if _, ok := builtins[s]; ok {
+			h.Builtin = s
+		} else {
+			h.Run = s
+		}
+		return nil
+	}
+
+	type plain Hook
+	return json.Unmarshal(data, (*plain)(h))
+}
+
+func (h Hook) String() string {
+	if h.Builtin != "" {
+		return h.Builtin
+	}
+	return h.Run
+}
+
+// hookList 事件上的钩子列表，也接受单个钩子的简写
+type hookList []Hook
+
+func (l *hookList) UnmarshalJSON(data []byte) error {
+	var list []Hook
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = list
+		return nil
+	}
+	var h Hook
+	if err := json.Unmarshal(data, &h); err != nil {
+		return err
+	}
+	*l = hookList{h}
+	return nil
+}
+
+// builtins 内置检查，失败时返回的错误描述未通过的原因
+var builtins = map[string]func(dir string) error{
+	"validate": runValidate,
+	"lint":     runLint,
+}
+
+// Runner 按 geelato.json 中 config.hooks 的声明执行钩子
+type Runner struct {
+	dir   string
+	hooks map[string]hookList
+}
+
+// Load 读取应用目录 geelato.json 中的 config.hooks，没有 geelato.json 或未声明钩子时返回空的 Runner
+func Load(dir string) (*Runner, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "geelato.json"))
+	if os.IsNotExist(err) {
+		return &Runner{dir: dir}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read geelato.json: %w", err)
+	}
+
+	var doc struct {
+		Config struct {
+			Hooks map[string]hookList `json:"hooks"`
+		} `json:"config"`
+		// Scripts 兼容旧结构：顶层 scripts
+		Scripts map[string]hookList `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, gerrors.Wrap(err, gerrors.ErrHookInvalid, "geelato.json 中的 config.hooks 格式无效")
+	}
+	hooks := doc.Config.Hooks
+	if hooks == nil {
+		hooks = doc.Scripts
+	}
+
+	for event, list := range hooks {
+		if !events[event] {
+			return nil, gerrors.New(gerrors.ErrHookInvalid,
+				fmt.Sprintf("未知的钩子事件 %s，可用事件: %s", event, strings.Join(Events(), ", ")))
+		}
+		for _, h := range list {
+			if (h.Builtin == "") == (h.Run == "") {
+				return nil, gerrors.New(gerrors.ErrHookInvalid,
+					fmt.Sprintf("%s 钩子必须且只能设置 builtin 或 run 之一", event))
+			}
+			if _, ok := builtins[h.Builtin]; h.Builtin != "" && !ok {
+				return nil, gerrors.New(gerrors.ErrHookInvalid,
+					fmt.Sprintf("未知的内置检查 %s，可用: validate, lint", h.Builtin))
+			}
+		}
+	}
+
+	return &Runner{dir: dir, hooks: hooks}, nil
+}
+
+// Events 返回支持的钩子事件名称
+func Events() []string {
+	names := make([]string, 0, len(events))
+	for e := range events {
+		names = append(names, e)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run 依次执行事件上的钩子，遇到第一个失败的钩子即停止并返回 ErrHookFailed。
+// env 中非空的值以 GEELATO_* 环境变量传给 shell 命令，GEELATO_HOOK 与 GEELATO_APP_DIR 总是会设置
+func (r *Runner) Run(event string, env map[string]string) error {
+	hooks := r.hooks[event]
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	logger.Infof("Running %s hooks...", event)
+	for _, h := range hooks {
+		logger.Infof("> %s", h)
+
+		var err error
+		if h.Builtin != "" {
+			err = builtins[h.Builtin](r.dir)
+		} else {
+			err = r.runShell(event, h.Run, env)
+		}
+		if err != nil {
+			return gerrors.Wrap(err, gerrors.ErrHookFailed, fmt.Sprintf("%s 钩子 %q 失败: %v", event, h.String(), err))
+		}
+	}
+	return nil
+}
+
+func (r *Runner) runShell(event, command string, env map[string]string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Dir = r.dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	cmd.Env = append(os.Environ(), "GEELATO_HOOK="+event, "GEELATO_APP_DIR="+r.dir)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if env[k] == "" {
+			continue
+		}
+		cmd.Env = append(cmd.Env, "GEELATO_"+k+"="+env[k])
+	}
+
+	return cmd.Run()
+}
+
+func runValidate(dir string) error {
+	result, err := app.NewValidator().Validate(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range result.Errors {
+		logger.Errorf("  - %s", e)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d validation errors", len(result.Errors))
+	}
+	return nil
+}
+
+func runLint(dir string) error {
+	issues, err := app.Lint(dir)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		logger.Errorf("  %s", issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d lint issues", len(issues))
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MetaChange 实体元数据文件中的一处语义变化
+type MetaChange struct {
+	// Kind 为 table、column、foreignKey 或 check
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Action 为 added、removed 或 changed
+	Action   string      `json:"action"`
+	Property string      `json:"property,omitempty"`
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+	// Summary 新增或删除时的概要，例如字段类型、外键引用
+	Summary string `json:"summary,omitempty"`
+}
+
+func (c MetaChange) String() string {
+	subject := fmt.Sprintf("%s `%s`", metaKindLabels[c.Kind], c.Name)
+	switch c.Action {
+	case "added", "removed":
+		if c.Summary != "" {
+			return fmt.Sprintf("%s %s (%s)", subject, c.Action, c.Summary)
+		}
+		return fmt.Sprintf("%s %s", subject, c.Action)
+	default:
+		return fmt.Sprintf("%s %s %s→%s", subject, propertyLabel(c.Property), formatMetaValue(c.Old), formatMetaValue(c.New))
+	}
+}
+
+var metaKindLabels = map[string]string{
+	"table":      "table",
+	"column":     "column",
+	"foreignKey": "FK",
+	"check":      "check",
+}
+
+// propertyLabels 常用属性的可读名称
+var propertyLabels = map[string]string{
+	"characterMaxinumLength": "length",
+	"columnType":             "type",
+	"dataType":               "data type",
+	"isNullable":             "nullable",
+	"isUnique":               "unique",
+	"columnDefault":          "default",
+	"columnComment":          "comment",
+	"numericPrecision":       "precision",
+	"numericScale":           "scale",
+	"ordinalPosition":        "position",
+	"deleteAction":           "on delete",
+	"updateAction":           "on update",
+}
+
+func propertyLabel(property string) string {
+	if label, ok := propertyLabels[property]; ok {
+		return label
+	}
+	return property
+}
+
+// metaFileKinds 元数据文件后缀与其中集合字段的对应关系
+var metaFileKinds = []struct {
+	suffix string
+	kind   string
+	field  string
+}{
+	{".define.json", "table", "table"},
+	{".columns.json", "column", "columns"},
+	{".fk.json", "foreignKey", "foreignKeys"},
+	{".check.json", "check", "checks"},
+}
+
+// IsMetaFile 判断路径是否为实体元数据文件
+func IsMetaFile(path string) bool {
+	for _, k := range metaFileKinds {
+		if strings.HasSuffix(path, k.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffMeta 比较同一元数据文件的两个版本，返回表、字段、外键与约束级别的变化。
+// 任一版本为 nil 表示文件不存在
+func DiffMeta(path string, old, new []byte) ([]MetaChange, error) {
+	for _, k := range metaFileKinds {
+		if !strings.HasSuffix(path, k.suffix) {
+			continue
+		}
+
+		oldDoc, err := decodeMetaDoc(old)
+		if err != nil {
+			return nil, err
+		}
+		newDoc, err := decodeMetaDoc(new)
+		if err != nil {
+			return nil, err
+		}
+
+		if k.kind == "table" {
+			return diffTable(asObject(oldDoc[k.field]), asObject(newDoc[k.field])), nil
+		}
+		return diffItems(k.kind, asArray(oldDoc[k.field]), asArray(newDoc[k.field])), nil
+	}
+	return nil, fmt.Errorf("不是实体元数据文件: %s", path)
+}
+
+func decodeMetaDoc(data []byte) (map[string]interface{}, error) {
+	if data == nil {
+		return map[string]interface{}{}, nil
+	}
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func diffTable(old, new map[string]interface{}) []MetaChange {
+	name := itemName("table", new)
+	if name == "" {
+		name = itemName("table", old)
+	}
+
+	switch {
+	case old == nil && new == nil:
+		return nil
+	case old == nil:
+		return []MetaChange{{Kind: "table", Name: name, Action: "added"}}
+	case new == nil:
+		return []MetaChange{{Kind: "table", Name: name, Action: "removed"}}
+	}
+	return diffProperties("table", name, old, new)
+}
+
+// diffItems 按 id 对齐字段、外键或约束，逐项比较
+func diffItems(kind string, old, new []interface{}) []MetaChange {
+	oldItems, oldOrder := indexItems(kind, old)
+	newItems, newOrder := indexItems(kind, new)
+
+	var changes []MetaChange
+	for _, key := range oldOrder {
+		o := oldItems[key]
+		n, exists := newItems[key]
+		if !exists {
+			changes = append(changes, MetaChange{Kind: kind, Name: itemName(kind, o), Action: "removed", Summary: itemSummary(kind, o)})
+			continue
+		}
+		changes = append(changes, diffProperties(kind, itemName(kind, n), o, n)...)
+	}
+	for _, key := range newOrder {
+		if _, exists := oldItems[key]; !exists {
+			n := newItems[key]
+			changes = append(changes, MetaChange{Kind: kind, Name: itemName(kind, n), Action: "added", Summary: itemSummary(kind, n)})
+		}
+	}
+	return changes
+}
+
+func diffProperties(kind, name string, old, new map[string]interface{}) []MetaChange {
+	keys := make([]string, 0, len(old)+len(new))
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if _, exists := old[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []MetaChange
+	for _, key := range keys {
+		if jsonString(old[key]) == jsonString(new[key]) {
+			continue
+		}
+		changes = append(changes, MetaChange{
+			Kind:     kind,
+			Name:     name,
+			Action:   "changed",
+			Property: key,
+			Old:      old[key],
+			New:      new[key],
+		})
+	}
+	return changes
+}
+
+func indexItems(kind string, items []interface{}) (map[string]map[string]interface{}, []string) {
+	index := make(map[string]map[string]interface{}, len(items))
+	order := make([]string, 0, len(items))
+	for i, item := range items {
+		obj := asObject(item)
+		if obj == nil {
+			continue
+		}
+		key, _ := obj["id"].(string)
+		if key == "" {
+			key = itemName(kind, obj)
+		}
+		if key == "" {
+			key = fmt.Sprintf("#%d", i)
+		}
+		index[key] = obj
+		order = append(order, key)
+	}
+	return index, order
+}
+
+// itemName 返回便于阅读的名称：字段用 fieldName，表用 tableName，其余用 id
+func itemName(kind string, item map[string]interface{}) string {
+	var candidates []string
+	switch kind {
+	case "column":
+		candidates = []string{"fieldName", "columnName", "name", "id"}
+	case "table":
+		candidates = []string{"tableName", "entityName", "id"}
+	default:
+		candidates = []string{"id", "name", "title"}
+	}
+	for _, key := range candidates {
+		if s, ok := item[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func itemSummary(kind string, item map[string]interface{}) string {
+	switch kind {
+	case "column":
+		if s, ok := item["columnType"].(string); ok {
+			return s
+		}
+	case "foreignKey":
+		return fmt.Sprintf("%v → %v.%v", item["mainTableCol"], item["foreignTable"], item["foreignTableCol"])
+	case "check":
+		if s, ok := item["checkClause"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func asObject(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	return obj
+}
+
+func asArray(v interface{}) []interface{} {
+	arr, _ := v.([]interface{})
+	return arr
+}
+
+func jsonString(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func formatMetaValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return jsonString(v)
+}
@@ -0,0 +1,130 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/geelato/cli/internal/auth"
+	"github.com/geelato/cli/internal/transport"
+)
+
+// DeviceCode 设备码登录流程中平台返回的授权信息
+type DeviceCode struct {
+	DeviceCode      string `json:"deviceCode"`
+	UserCode        string `json:"userCode"`
+	VerificationURI string `json:"verificationUri"`
+	ExpiresIn       int    `json:"expiresIn"`
+	Interval        int    `json:"interval"`
+}
+
+// BaseURL 返回客户端连接的平台地址
+func (c *Client) BaseURL() string {
+	return c.client.BaseURL()
+}
+
+// Login 使用用户名和密码换取访问令牌
+func (c *Client) Login(ctx context.Context, username, password string) (*auth.Token, error) {
+	resp, err := c.Request(ctx, transport.Request{
+		Method: http.MethodPost,
+		Path:   "/api/cli/auth/login",
+		Body: map[string]interface{}{
+			"username": username,
+			"password": password,
+		},
+		NoAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result auth.TokenResponse
+	if err := resp.Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Username == "" {
+		result.Username = username
+	}
+
+	return result.Token(c.BaseURL())
+}
+
+// RequestDeviceCode 发起设备码登录，返回用户需要在浏览器中确认的授权码
+func (c *Client) RequestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	resp, err := c.Request(ctx, transport.Request{
+		Method: http.MethodPost,
+		Path:   "/api/cli/auth/device/code",
+		Body:   map[string]interface{}{},
+		NoAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result DeviceCode
+	if err := resp.Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Interval <= 0 {
+		result.Interval = 5
+	}
+	if result.ExpiresIn <= 0 {
+		result.ExpiresIn = 600
+	}
+
+	return &result, nil
+}
+
+// PollDeviceToken 轮询设备码授权结果，直到用户确认、超时或被拒绝
+func (c *Client) PollDeviceToken(ctx context.Context, code *DeviceCode) (*auth.Token, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		resp, err := c.Request(ctx, transport.Request{
+			Method: http.MethodPost,
+			Path:   "/api/cli/auth/device/token",
+			Body: map[string]interface{}{
+				"deviceCode": code.DeviceCode,
+			},
+			NoAuth: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var result auth.TokenResponse
+		if err := resp.Decode(&result); err != nil {
+			return nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+
+		switch result.Error {
+		case "":
+			return result.Token(c.BaseURL())
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		case "access_denied":
+			return nil, fmt.Errorf("授权被拒绝")
+		default:
+			return nil, fmt.Errorf("设备码登录失败: %s", result.Error)
+		}
+	}
+
+	return nil, fmt.Errorf("设备码已过期，请重新登录")
+}
+
+// Logout 在平台端吊销当前访问令牌
+func (c *Client) Logout(ctx context.Context) error {
+	_, err := c.Post(ctx, "/api/cli/auth/logout", map[string]interface{}{})
+	return err
+}
@@ -1,56 +1,55 @@
 package platform
 
 import (
-	"bytes"
+	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/internal/transport"
 	"github.com/geelato/cli/pkg/crypto"
+	"github.com/geelato/cli/pkg/utils"
 )
 
+// Client 平台 API 客户端，请求经由 transport 包统一发送
 type Client struct {
-	baseURL string
-	apiKey  string
-	client  *http.Client
-	headers map[string]string
-}
-
-type RequestOptions struct {
-	Method      string
-	Path        string
-	Body        interface{}
-	QueryParams map[string]string
-	Headers     map[string]string
-	Timeout     time.Duration
-}
-
-type Response struct {
-	StatusCode int
-	Headers    http.Header
-	Body       []byte
-	Data       interface{}
+	client *transport.Client
 }
 
 type UploadRequest struct {
 	AppID   string
 	Version string
-	Branch  string
-	Message string
-	Author  string
-	Files   []FileEntry
-}
+	// BaseVersion 本次变更所基于的平台版本，为空表示全量上传
+	BaseVersion string
+	Branch      string
+	Message     string
+	Author      string
+	Files       []FileEntry
+	// Force 忽略与平台版本的冲突，强制覆盖
+	Force bool
+	// MergeFrom 本次上传包含的合并来源版本，平台据此把该版本记入分支历史
+	MergeFrom string
+}
+
+// FileAction 增量上传时文件的操作类型
+const (
+	FileActionAdd    = "added"
+	FileActionModify = "modified"
+	FileActionDelete = "deleted"
+)
 
 type FileEntry struct {
-	Path    string
-	Content []byte
-	Hash    string
+	Path    string `json:"path"`
+	Content []byte `json:"content,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+	// Action 增量上传时的操作类型，删除的文件不携带内容
+	Action string `json:"action,omitempty"`
 }
 
 type SyncStatus struct {
@@ -75,159 +74,84 @@ type ConflictCheckResult struct {
 }
 
 func NewClient() *Client {
-	cfg := config.Get()
-	return NewClientWithConfig(cfg)
+	return NewClientWithEndpoint(config.Get().Endpoint())
 }
 
 func NewClientWithConfig(cfg *config.Config) *Client {
-	return &Client{
-		baseURL: cfg.API.URL,
-		apiKey:  cfg.API.Key,
-		client: &http.Client{
-			Timeout: time.Duration(cfg.API.Timeout) * time.Second,
-		},
-		headers: make(map[string]string),
-	}
+	return NewClientWithEndpoint(cfg.Endpoint())
 }
 
-func NewClientWithURL(apiURL string) *Client {
-	timeout := 30
-	if cfg := config.Get(); cfg != nil {
-		timeout = cfg.API.Timeout
-	}
-	return &Client{
-		baseURL: apiURL,
-		apiKey:  "",
-		client: &http.Client{
-			Timeout: time.Duration(timeout) * time.Second,
-		},
-		headers: make(map[string]string),
-	}
+// NewClientWithEndpoint 使用已解析的平台地址创建客户端
+func NewClientWithEndpoint(ep *config.Endpoint) *Client {
+	return &Client{client: transport.NewWithEndpoint(ep)}
 }
 
-func (c *Client) SetHeader(key, value string) {
-	c.headers[key] = value
+func NewClientWithURL(apiURL string) *Client {
+	ep := config.Get().Endpoint()
+	ep.URL = apiURL
+	ep.Key = ""
+	return NewClientWithEndpoint(ep)
 }
 
-func (c *Client) SetAuthToken(token string) {
-	c.apiKey = token
-	c.headers["Authorization"] = "Bearer " + token
+// Transport 返回底层的统一 HTTP 客户端，可用于追加中间件
+func (c *Client) Transport() *transport.Client {
+	return c.client
 }
 
-func (c *Client) Request(ctx context.Context, opts RequestOptions) (*Response, error) {
-	if opts.Timeout == 0 {
-		opts.Timeout = 30 * time.Second
-	}
-
-	url := c.baseURL + opts.Path
-
-	var bodyReader io.Reader
-	var contentType string
-
-	if opts.Body != nil {
-		data, err := json.Marshal(opts.Body)
-		if err != nil {
-			return nil, fmt.Errorf("序列化请求体失败: %w", err)
-		}
-		bodyReader = bytes.NewReader(data)
-		contentType = "application/json"
-	}
-
-	req, err := http.NewRequest(opts.Method, url, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %w", err)
-	}
-
-	req = req.WithContext(ctx)
-	req.Header.Set("Content-Type", contentType)
-
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
-	}
-	for k, v := range opts.Headers {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应体失败: %w", err)
-	}
-
-	response := &Response{
-		StatusCode: resp.StatusCode,
-		Headers:    resp.Header.Clone(),
-		Body:       body,
-	}
-
-	if resp.StatusCode >= 400 {
-		return response, c.handleError(resp.StatusCode, body)
-	}
-
-	return response, nil
+func (c *Client) SetHeader(key, value string) {
+	c.client.SetHeader(key, value)
 }
 
-func (c *Client) handleError(statusCode int, body []byte) error {
-	var errResp map[string]interface{}
-	json.Unmarshal(body, &errResp)
-
-	message := "未知错误"
-	if msg, ok := errResp["message"].(string); ok {
-		message = msg
-	}
+func (c *Client) SetAuthToken(token string) {
+	c.client.Credentials().SetAccessToken(token)
+}
 
-	switch statusCode {
-	case 401:
-		return fmt.Errorf("认证失败: %s", message)
-	case 403:
-		return fmt.Errorf("权限不足: %s", message)
-	case 404:
-		return fmt.Errorf("资源不存在: %s", message)
-	case 409:
-		return fmt.Errorf("同步冲突: %s", message)
-	default:
-		return fmt.Errorf("服务器错误 %d: %s", statusCode, message)
-	}
+// Request 发送请求。令牌刷新、重试与错误码映射由 transport 中间件处理
+func (c *Client) Request(ctx context.Context, req transport.Request) (*transport.Response, error) {
+	return c.client.Do(ctx, req)
 }
 
-func (c *Client) Get(ctx context.Context, path string) (*Response, error) {
-	return c.Request(ctx, RequestOptions{
+func (c *Client) Get(ctx context.Context, path string) (*transport.Response, error) {
+	return c.Request(ctx, transport.Request{
 		Method: http.MethodGet,
 		Path:   path,
 	})
 }
 
-func (c *Client) Post(ctx context.Context, path string, body interface{}) (*Response, error) {
-	return c.Request(ctx, RequestOptions{
+func (c *Client) Post(ctx context.Context, path string, body interface{}) (*transport.Response, error) {
+	return c.Request(ctx, transport.Request{
 		Method: http.MethodPost,
 		Path:   path,
 		Body:   body,
 	})
 }
 
-func (c *Client) Put(ctx context.Context, path string, body interface{}) (*Response, error) {
-	return c.Request(ctx, RequestOptions{
+func (c *Client) Put(ctx context.Context, path string, body interface{}) (*transport.Response, error) {
+	return c.Request(ctx, transport.Request{
 		Method: http.MethodPut,
 		Path:   path,
 		Body:   body,
 	})
 }
 
-func (c *Client) Delete(ctx context.Context, path string) (*Response, error) {
-	return c.Request(ctx, RequestOptions{
+func (c *Client) Delete(ctx context.Context, path string) (*transport.Response, error) {
+	return c.Request(ctx, transport.Request{
 		Method: http.MethodDelete,
 		Path:   path,
 	})
 }
 
+// packageTimeout 上传、下载整个应用包的超时，应用包可能较大，不受 api.timeout 限制
+const packageTimeout = 5 * time.Minute
+
 func (c *Client) UploadAppPackage(ctx context.Context, req *UploadRequest) (string, error) {
 	files := make([]FileEntry, 0, len(req.Files))
 	for _, f := range req.Files {
+		if f.Action == FileActionDelete {
+			files = append(files, f)
+			continue
+		}
+
 		content := f.Content
 		if content == nil && f.Path != "" {
 			data, err := os.ReadFile(f.Path)
@@ -246,22 +170,38 @@ func (c *Client) UploadAppPackage(ctx context.Context, req *UploadRequest) (stri
 			Path:    f.Path,
 			Content: content,
 			Hash:    hash,
+			Action:  f.Action,
 		})
 	}
 
 	body := map[string]interface{}{
-		"appId":   req.AppID,
-		"version": req.Version,
-		"branch":  req.Branch,
-		"message": req.Message,
-		"author":  req.Author,
-		"files":   files,
+		"appId":       req.AppID,
+		"version":     req.Version,
+		"baseVersion": req.BaseVersion,
+		"branch":      req.Branch,
+		"message":     req.Message,
+		"author":      req.Author,
+		"files":       files,
+	}
+	if req.Force {
+		body["force"] = true
+	}
+	if req.MergeFrom != "" {
+		body["mergeFrom"] = req.MergeFrom
 	}
 
-	resp, err := c.Request(ctx, RequestOptions{
-		Method: http.MethodPost,
-		Path:   "/api/cli/app/upload",
-		Body:   body,
+	// 同一次上传的所有重试共用一个幂等键，避免服务端重复发布版本
+	key, err := utils.RandomHex(16)
+	if err != nil {
+		return "", fmt.Errorf("生成幂等键失败: %w", err)
+	}
+
+	resp, err := c.Request(ctx, transport.Request{
+		Method:         http.MethodPost,
+		Path:           "/api/cli/app/upload",
+		Body:           body,
+		IdempotencyKey: key,
+		Timeout:        packageTimeout,
 	})
 	if err != nil {
 		return "", err
@@ -270,7 +210,7 @@ func (c *Client) UploadAppPackage(ctx context.Context, req *UploadRequest) (stri
 	var result struct {
 		Version string `json:"version"`
 	}
-	if err := json.Unmarshal(resp.Body, &result); err != nil {
+	if err := resp.Decode(&result); err != nil {
 		return "", fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -279,7 +219,7 @@ func (c *Client) UploadAppPackage(ctx context.Context, req *UploadRequest) (stri
 
 func (c *Client) DownloadAppPackage(ctx context.Context, appID, version, outputDir string) error {
 	path := fmt.Sprintf("/api/cli/app/download?appId=%s&version=%s", appID, version)
-	resp, err := c.Request(ctx, RequestOptions{
+	resp, err := c.Request(ctx, transport.Request{
 		Method: http.MethodGet,
 		Path:   path,
 	})
@@ -290,33 +230,41 @@ func (c *Client) DownloadAppPackage(ctx context.Context, appID, version, outputD
 	return os.WriteFile(filepath.Join(outputDir, "app.zip"), resp.Body, 0644)
 }
 
-func (c *Client) CheckConflict(ctx context.Context, appID, version string, files []FileEntry) (*ConflictCheckResult, error) {
+func (c *Client) CheckConflict(ctx context.Context, appID, branch, version string, files []FileEntry) (*ConflictCheckResult, error) {
 	body := map[string]interface{}{
 		"appId":   appID,
 		"version": version,
 		"files":   files,
 	}
+	if branch != "" {
+		body["branch"] = branch
+	}
 
-	resp, err := c.Request(ctx, RequestOptions{
-		Method: http.MethodPost,
-		Path:   "/api/cli/app/check-conflict",
-		Body:   body,
+	resp, err := c.Request(ctx, transport.Request{
+		Method:     http.MethodPost,
+		Path:       "/api/cli/app/check-conflict",
+		Body:       body,
+		Idempotent: true,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var result ConflictCheckResult
-	if err := json.Unmarshal(resp.Body, &result); err != nil {
+	if err := resp.Decode(&result); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
 	return &result, nil
 }
 
-func (c *Client) GetSyncStatus(ctx context.Context, appID string) (*SyncStatus, error) {
+// GetSyncStatus 查询分支上的最新版本，branch 为空时为平台默认分支
+func (c *Client) GetSyncStatus(ctx context.Context, appID, branch string) (*SyncStatus, error) {
 	path := fmt.Sprintf("/api/cli/app/status?appId=%s", appID)
-	resp, err := c.Request(ctx, RequestOptions{
+	if branch != "" {
+		path += "&branch=" + url.QueryEscape(branch)
+	}
+	resp, err := c.Request(ctx, transport.Request{
 		Method: http.MethodGet,
 		Path:   path,
 	})
@@ -325,15 +273,164 @@ func (c *Client) GetSyncStatus(ctx context.Context, appID string) (*SyncStatus,
 	}
 
 	var status SyncStatus
-	if err := json.Unmarshal(resp.Body, &status); err != nil {
+	if err := resp.Decode(&status); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
 	return &status, nil
 }
 
+// WatchEvents 订阅分支上的版本发布通知（text/event-stream），每条 data 为一个 SyncStatus，
+// 收到后调用 onEvent，直到连接断开或 ctx 结束。平台不支持通知时返回错误，调用方应改为轮询 GetSyncStatus
+func (c *Client) WatchEvents(ctx context.Context, appID, branch string, onEvent func(SyncStatus)) error {
+	query := map[string]string{"appId": appID}
+	if branch != "" {
+		query["branch"] = branch
+	}
+	resp, err := c.client.Stream(ctx, transport.Request{
+		Method:  http.MethodGet,
+		Path:    "/api/cli/app/events",
+		Query:   query,
+		Headers: map[string]string{"Accept": "text/event-stream"},
+		Timeout: 24 * time.Hour,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		return fmt.Errorf("平台不支持变更通知: Content-Type 为 %q", ct)
+	}
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				var status SyncStatus
+				if err := transport.Decode([]byte(data.String()), &status); err == nil && status.Version != "" {
+					onEvent(status)
+				}
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// ListVersions 按发布时间倒序返回分支的版本历史，limit 为 0 时返回全部
+func (c *Client) ListVersions(ctx context.Context, appID, branch string, limit int) ([]SyncStatus, error) {
+	path := fmt.Sprintf("/api/cli/app/versions?appId=%s", appID)
+	if branch != "" {
+		path += "&branch=" + url.QueryEscape(branch)
+	}
+	if limit > 0 {
+		path += fmt.Sprintf("&limit=%d", limit)
+	}
+	resp, err := c.Request(ctx, transport.Request{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []SyncStatus
+	if err := resp.Decode(&versions); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return versions, nil
+}
+
+// Branch 平台上的应用分支
+type Branch struct {
+	Name string `json:"name"`
+	// Version 分支上的最新版本
+	Version string `json:"version"`
+	// From 创建分支时的来源分支
+	From      string    `json:"from"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (c *Client) ListBranches(ctx context.Context, appID string) ([]Branch, error) {
+	path := fmt.Sprintf("/api/cli/app/branches?appId=%s", appID)
+	resp, err := c.Request(ctx, transport.Request{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []Branch
+	if err := resp.Decode(&branches); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return branches, nil
+}
+
+// CreateBranch 从来源分支的指定版本创建分支，version 为空时取来源分支的最新版本
+func (c *Client) CreateBranch(ctx context.Context, appID, name, from, version string) (*Branch, error) {
+	body := map[string]interface{}{
+		"appId":   appID,
+		"name":    name,
+		"from":    from,
+		"version": version,
+	}
+
+	// 重试共用一个幂等键，避免首次请求已成功时重试因分支已存在而失败
+	key, err := utils.RandomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("生成幂等键失败: %w", err)
+	}
+
+	resp, err := c.Request(ctx, transport.Request{
+		Method:         http.MethodPost,
+		Path:           "/api/cli/app/branch/create",
+		Body:           body,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var branch Branch
+	if err := resp.Decode(&branch); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return &branch, nil
+}
+
+func (c *Client) DeleteBranch(ctx context.Context, appID, name string) error {
+	_, err := c.Request(ctx, transport.Request{
+		Method: http.MethodPost,
+		Path:   "/api/cli/app/branch/delete",
+		Body: map[string]interface{}{
+			"appId": appID,
+			"name":  name,
+		},
+		Idempotent: true,
+	})
+	return err
+}
+
 func (c *Client) Ping(ctx context.Context) error {
-	_, err := c.Request(ctx, RequestOptions{
+	_, err := c.Request(ctx, transport.Request{
 		Method:  http.MethodGet,
 		Path:    "/health",
 		Timeout: 10 * time.Second,
@@ -345,11 +442,7 @@ type CloneRequest struct {
 	AppCode string `json:"appCode"`
 	Tenant  string `json:"tenant"`
 	Version string `json:"version"`
-}
-
-type CloneResponse struct {
-	Format string            `json:"format"`
-	Data   CloneResponseData `json:"data"`
+	Branch  string `json:"branch,omitempty"`
 }
 
 type CloneResponseData struct {
@@ -501,24 +594,28 @@ type WorkflowData struct {
 	Definition string `json:"definition"`
 }
 
-func (c *Client) CloneApp(ctx context.Context, req *CloneRequest) (*CloneResponse, error) {
+func (c *Client) CloneApp(ctx context.Context, req *CloneRequest) (*CloneResponseData, error) {
 	body := map[string]interface{}{
 		"appCode": req.AppCode,
 		"tenant":  req.Tenant,
 		"version": req.Version,
 	}
+	if req.Branch != "" {
+		body["branch"] = req.Branch
+	}
 
-	resp, err := c.Request(ctx, RequestOptions{
-		Method: http.MethodPost,
-		Path:   "/api/cli/app/clone",
-		Body:   body,
+	resp, err := c.Request(ctx, transport.Request{
+		Method:     http.MethodPost,
+		Path:       "/api/cli/app/clone",
+		Body:       body,
+		Idempotent: true,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	var result CloneResponse
-	if err := json.Unmarshal(resp.Body, &result); err != nil {
+	var result CloneResponseData
+	if err := resp.Decode(&result); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -527,9 +624,10 @@ func (c *Client) CloneApp(ctx context.Context, req *CloneRequest) (*CloneRespons
 
 func (c *Client) DownloadAppPackageBytes(ctx context.Context, appID, version string) ([]byte, error) {
 	path := fmt.Sprintf("/api/cli/app/download?appId=%s&version=%s", appID, version)
-	resp, err := c.Request(ctx, RequestOptions{
-		Method: http.MethodGet,
-		Path:   path,
+	resp, err := c.Request(ctx, transport.Request{
+		Method:  http.MethodGet,
+		Path:    path,
+		Timeout: packageTimeout,
 	})
 	if err != nil {
 		return nil, err
@@ -537,3 +635,66 @@ func (c *Client) DownloadAppPackageBytes(ctx context.Context, appID, version str
 
 	return resp.Body, nil
 }
+
+// PagePreviewRequest 页面预览渲染请求，Content 为本地的页面源码
+type PagePreviewRequest struct {
+	AppID   string `json:"appId"`
+	Code    string `json:"code"`
+	Content string `json:"content"`
+}
+
+// RenderPagePreview 使用平台的页面渲染器把页面源码渲染为 HTML，页面无需先推送
+func (c *Client) RenderPagePreview(ctx context.Context, req *PagePreviewRequest) (string, error) {
+	resp, err := c.Request(ctx, transport.Request{
+		Method:     http.MethodPost,
+		Path:       "/api/cli/page/preview",
+		Body:       req,
+		Idempotent: true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		HTML string `json:"html"`
+	}
+	if err := resp.Decode(&result); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	return result.HTML, nil
+}
+
+// APIRunRequest 在平台上执行 API 脚本的请求，Script 为本地的脚本源码
+type APIRunRequest struct {
+	AppID  string                 `json:"appId"`
+	Path   string                 `json:"path"`
+	Script string                 `json:"script"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// APIRunResult 平台执行 API 脚本的结果
+type APIRunResult struct {
+	Result interface{} `json:"result"`
+	Logs   []struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	} `json:"logs,omitempty"`
+}
+
+// RunAPIScript 在开发环境中执行本地的 API 脚本，访问平台上的真实数据，脚本无需先推送
+func (c *Client) RunAPIScript(ctx context.Context, req *APIRunRequest) (*APIRunResult, error) {
+	resp, err := c.Request(ctx, transport.Request{
+		Method: http.MethodPost,
+		Path:   "/api/cli/api/run",
+		Body:   req,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result APIRunResult
+	if err := resp.Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,152 @@
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// textProps 本地渲染时作为组件文字显示的属性
+var textProps = []string{"label", "title", "text", "content", "placeholder"}
+
+// renderLocal 不依赖平台，把页面组件树渲染为带组件名、id 与简单属性的结构化 HTML，用于检查页面结构
+func renderLocal(page *Page) string {
+	var doc interface{}
+	if err := json.Unmarshal(page.Source, &doc); err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"><style>
+body{font:13px/1.5 -apple-system,"Segoe UI",sans-serif;margin:12px;color:#1d2129}
+.gl-node{border:1px dashed #c9cdd4;border-radius:4px;padding:6px 8px;margin:6px 0}
+.gl-name{font-weight:600;color:#165dff}.gl-id{color:#86909c;margin-left:6px}
+.gl-text{margin:4px 0}.gl-props{margin:4px 0;color:#4e5969;font-size:12px}
+.gl-props span{display:inline-block;margin-right:12px}
+</style></head><body>`)
+	if root, ok := doc.(map[string]interface{}); ok {
+		renderNode(&b, root)
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func renderNode(b *strings.Builder, node map[string]interface{}) {
+	name, _ := node["componentName"].(string)
+	if name == "" {
+		name = "?"
+	}
+	b.WriteString(`<div class="gl-node"><div><span class="gl-name">` + html.EscapeString(name) + `</span>`)
+	if id, _ := node["id"].(string); id != "" {
+		b.WriteString(`<span class="gl-id">#` + html.EscapeString(id) + `</span>`)
+	}
+	b.WriteString("</div>")
+
+	props, _ := node["props"].(map[string]interface{})
+	for _, key := range textProps {
+		if text, ok := props[key].(string); ok && text != "" {
+			b.WriteString(`<div class="gl-text">` + html.EscapeString(text) + `</div>`)
+			break
+		}
+	}
+
+	if len(props) > 0 {
+		keys := make([]string, 0, len(props))
+		for k := range props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString(`<div class="gl-props">`)
+		for _, k := range keys {
+			b.WriteString("<span>" + html.EscapeString(k) + "=" + html.EscapeString(propValue(props[k])) + "</span>")
+		}
+		b.WriteString("</div>")
+	}
+
+	children, _ := node["children"].([]interface{})
+	for _, c := range children {
+		if child, ok := c.(map[string]interface{}); ok {
+			renderNode(b, child)
+		}
+	}
+	b.WriteString("</div>")
+}
+
+func propValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if r := []rune(v); len(r) > 40 {
+			return fmt.Sprintf("%q…", string(r[:40]))
+		}
+		return fmt.Sprintf("%q", v)
+	case map[string]interface{}:
+		return "{…}"
+	case []interface{}:
+		return fmt.Sprintf("[%d]", len(v))
+	}
+	return fmt.Sprint(v)
+}
+
+// reloadScript 连接 /ws，收到当前页面（page 为空时为任意页面）的 reload 消息后刷新，断开后自动重连
+const reloadScript = `<script>
+(function () {
+  var page = {{.}};
+  function connect() {
+    var ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/ws");
+    ws.onmessage = function (e) {
+      var msg = JSON.parse(e.data);
+      if (msg.type === "reload" && (!page || !msg.page || msg.page === page)) {
+        location.reload();
+      }
+    };
+    ws.onclose = function () { setTimeout(connect, 1000); };
+  }
+  connect();
+})();
+</script>`
+
+const pageStyle = `<style>
+body{font:14px/1.5 -apple-system,"Segoe UI",sans-serif;margin:0;color:#1d2129;background:#f2f3f5}
+header{display:flex;gap:16px;align-items:center;padding:10px 16px;background:#fff;border-bottom:1px solid #e5e6eb}
+header a{color:#165dff;text-decoration:none}header .meta{color:#86909c;font-size:12px}
+.issues{margin:12px 16px;padding:10px 14px;background:#fff2f0;border:1px solid #f53f3f;border-radius:4px;color:#cb272d}
+.issues h3{margin:0 0 6px;font-size:14px}.issues pre{margin:0;white-space:pre-wrap;font:12px/1.6 monospace}
+.notice{margin:12px 16px;padding:8px 14px;background:#fff7e8;border:1px solid #ff7d00;border-radius:4px;color:#d25f00;font-size:12px}
+.empty{margin:40px;text-align:center;color:#86909c}
+iframe{display:block;width:calc(100% - 32px);height:calc(100vh - 140px);margin:12px 16px;border:1px solid #e5e6eb;background:#fff}
+ul{margin:16px 32px}li{margin:4px 0}
+</style>`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Geelato page preview</title>` + pageStyle + `</head>
+<body>
+<header><strong>Geelato page preview</strong></header>
+{{if .Pages}}<ul>{{range .Pages}}<li><a href="/page/{{.}}">{{.}}</a></li>{{end}}</ul>
+{{else}}<div class="empty">No pages yet. Create one with <code>geelato page create &lt;name&gt;</code>.</div>{{end}}
+{{template "reload" ""}}
+</body></html>`))
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Page.Title}} - Geelato page preview</title>` + pageStyle + `</head>
+<body>
+<header><a href="/">&larr; Pages</a><strong>{{.Page.Title}}</strong>
+<span class="meta">{{.Page.SourceFile}}{{if .Renderer}} &middot; rendered by {{.Renderer}}{{end}}</span></header>
+{{if .Issues}}<div class="issues"><h3>{{len .Issues}} problem(s)</h3><pre>{{range .Issues}}{{.}}
+{{end}}</pre></div>{{end}}
+{{if .RenderError}}<div class="notice">Platform preview failed, showing the local renderer: {{.RenderError}}</div>{{end}}
+{{if .HTML}}<iframe srcdoc="{{.HTML}}"></iframe>
+{{else if not .Page.Source}}<div class="empty">The page source is empty. Save a design to the page's .source.json file to preview it.</div>
+{{else}}<div class="empty">Fix the problems above to preview the page.</div>{{end}}
+{{template "reload" .Page.Name}}
+</body></html>`))
+
+func init() {
+	for _, t := range []*template.Template{indexTemplate, pageTemplate} {
+		template.Must(t.New("reload").Parse(reloadScript))
+	}
+}
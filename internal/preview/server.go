@@ -0,0 +1,257 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/platform"
+	"github.com/geelato/cli/pkg/logger"
+)
+
+// Options 预览服务参数
+type Options struct {
+	// Platform 平台客户端，为 nil 时只使用本地渲染器
+	Platform *platform.Client
+	AppID    string
+}
+
+// Server 本地页面预览服务：渲染 page/ 下的页面并内嵌检查结果，通过 WebSocket 通知浏览器重新加载
+type Server struct {
+	root string
+	opts Options
+
+	mu      sync.Mutex
+	clients map[*wsConn]bool
+}
+
+// Page 一个页面目录中的文件
+type Page struct {
+	// Name 页面目录名
+	Name  string
+	Code  string
+	Title string
+	// SourceFile 用于渲染的文件，source 为空时使用 preview
+	SourceFile string
+	Source     []byte
+}
+
+func NewServer(root string, opts Options) *Server {
+	return &Server{
+		root:    root,
+		opts:    opts,
+		clients: make(map[*wsConn]bool),
+	}
+}
+
+// Handler 返回预览服务的路由：/ 页面列表，/page/<name> 页面预览，/ws 重新加载通知
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/page/", s.handlePage)
+	mux.HandleFunc("/ws", s.handleWS)
+	return mux
+}
+
+// Reload 通知浏览器重新加载，page 为空时所有打开的页面都重新加载
+func (s *Server) Reload(page string) {
+	msg, _ := json.Marshal(map[string]string{"type": "reload", "page": page})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		if err := c.WriteText(msg); err != nil {
+			c.Close()
+			delete(s.clients, c)
+		}
+	}
+}
+
+// Close 断开所有 WebSocket 连接，http.Server.Shutdown 不会关闭被接管的连接
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		c.Close()
+		delete(s.clients, c)
+	}
+}
+
+// Pages 返回 page/ 下的页面目录名
+func (s *Server) Pages() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, "page"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			pages = append(pages, entry.Name())
+		}
+	}
+	sort.Strings(pages)
+	return pages, nil
+}
+
+// LoadPage 读取页面目录，define 中的 code 与 title 缺失时使用目录名
+func (s *Server) LoadPage(name string) (*Page, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, os.ErrNotExist
+	}
+	dir := filepath.Join(s.root, "page", name)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, os.ErrNotExist
+	}
+
+	page := &Page{Name: name, Code: name, Title: name}
+	if data := readFirst(dir, "*.define.json"); data != nil {
+		var define struct {
+			Page struct {
+				Code  string `json:"code"`
+				Title string `json:"title"`
+			} `json:"page"`
+		}
+		if json.Unmarshal(data, &define) == nil {
+			if define.Page.Code != "" {
+				page.Code = define.Page.Code
+			}
+			if define.Page.Title != "" {
+				page.Title = define.Page.Title
+			}
+		}
+	}
+
+	for _, pattern := range []string{"*.source.json", "*.preview.json"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		if len(matches) == 0 {
+			continue
+		}
+		data, err := os.ReadFile(matches[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			page.SourceFile = "page/" + name + "/" + filepath.Base(matches[0])
+			page.Source = data
+			break
+		}
+	}
+	return page, nil
+}
+
+func readFirst(dir, pattern string) []byte {
+	matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+	if len(matches) == 0 {
+		return nil
+	}
+	data, _ := os.ReadFile(matches[0])
+	return data
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	pages, err := s.Pages()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.execute(w, indexTemplate, map[string]interface{}{"Pages": pages})
+}
+
+// pageView 页面预览模板的数据
+type pageView struct {
+	Page     *Page
+	Issues   []app.LintIssue
+	Renderer string
+	// RenderError 平台渲染失败的原因，此时退回本地渲染
+	RenderError string
+	HTML        string
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/page/")
+	page, err := s.LoadPage(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	view := &pageView{Page: page}
+	issues, err := app.LintPage(s.root, name)
+	if err != nil {
+		view.Issues = []app.LintIssue{{Path: "page/" + name, Message: err.Error()}}
+	} else {
+		view.Issues = issues
+	}
+
+	if page.Source != nil && json.Valid(page.Source) {
+		view.HTML, view.Renderer, view.RenderError = s.render(r.Context(), page)
+	}
+	s.execute(w, pageTemplate, view)
+}
+
+// render 优先使用平台渲染，失败时退回本地渲染并返回失败原因
+func (s *Server) render(ctx context.Context, page *Page) (html, renderer, renderErr string) {
+	if s.opts.Platform != nil {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		html, err := s.opts.Platform.RenderPagePreview(ctx, &platform.PagePreviewRequest{
+			AppID:   s.opts.AppID,
+			Code:    page.Code,
+			Content: string(page.Source),
+		})
+		if err == nil {
+			return html, "platform", ""
+		}
+		logger.Warnf("Platform preview of %s failed, using the local renderer: %v", page.Name, err)
+		renderErr = err.Error()
+	}
+	return renderLocal(page), "local", renderErr
+}
+
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.clients[conn] = true
+	s.mu.Unlock()
+
+	conn.readLoop()
+
+	s.mu.Lock()
+	delete(s.clients, conn)
+	s.mu.Unlock()
+	conn.Close()
+}
+
+func (s *Server) execute(w http.ResponseWriter, tmpl *template.Template, data interface{}) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		http.Error(w, fmt.Sprintf("render failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(buf.Bytes())
+}
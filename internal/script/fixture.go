@@ -0,0 +1,407 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/geelato/cli/internal/sync"
+)
+
+// 测试用例文件的后缀，放在 API 脚本旁边，如 getList.api.js 对应 getList.api.test.json
+const (
+	FixtureSuffix   = ".api.test.json"
+	FixtureJSSuffix = ".api.test.js"
+)
+
+// Fixture 一个 API 脚本的测试用例文件
+type Fixture struct {
+	// Path 用例文件路径
+	Path string `json:"-"`
+	// API 被测脚本，相对用例文件所在目录，为空时由用例文件名推出
+	API string `json:"api,omitempty"`
+	// Mocks 所有用例共用的模拟数据
+	Mocks *Mocks `json:"mocks,omitempty"`
+	Cases []Case `json:"cases"`
+}
+
+// Case 一个测试用例
+type Case struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	// Mocks 与文件级的模拟数据合并，同名实体、会话与缓存的键以用例为准
+	Mocks *Mocks `json:"mocks,omitempty"`
+	// Expect 期望的返回值。默认只比较其中列出的字段，Exact 为 true 时要求完全一致
+	Expect json.RawMessage `json:"expect,omitempty"`
+	Exact  bool            `json:"exact,omitempty"`
+	// ExpectEntities 运行后期望的实体数据，按与 Expect 相同的规则比较
+	ExpectEntities map[string][]map[string]interface{} `json:"expectEntities,omitempty"`
+	// ExpectError 期望脚本抛出包含该文本的异常
+	ExpectError string `json:"expectError,omitempty"`
+}
+
+// ScriptPath 返回被测脚本的路径
+func (f *Fixture) ScriptPath() string {
+	if f.API != "" {
+		return filepath.Join(filepath.Dir(f.Path), filepath.FromSlash(f.API))
+	}
+	base := filepath.Base(f.Path)
+	for _, suffix := range []string{FixtureSuffix, FixtureJSSuffix} {
+		if strings.HasSuffix(base, suffix) {
+			base = strings.TrimSuffix(base, suffix) + ".api.js"
+			break
+		}
+	}
+	return filepath.Join(filepath.Dir(f.Path), base)
+}
+
+// IsFixture 判断文件名是否为测试用例文件
+func IsFixture(name string) bool {
+	return strings.HasSuffix(name, FixtureSuffix) || strings.HasSuffix(name, FixtureJSSuffix)
+}
+
+// FindFixtures 查找测试用例文件。paths 可以是目录、用例文件或 API 脚本（查找其旁边的用例文件）
+func FindFixtures(paths []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var found []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			found = append(found, path)
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case info.IsDir():
+			err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() && p != path && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				if !d.IsDir() && IsFixture(d.Name()) {
+					add(p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		case IsFixture(path):
+			add(path)
+		case strings.HasSuffix(path, ".api.js"):
+			prefix := strings.TrimSuffix(path, ".api.js")
+			for _, candidate := range []string{prefix + FixtureSuffix, prefix + FixtureJSSuffix} {
+				if _, err := os.Stat(candidate); err == nil {
+					add(candidate)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%s is not an API script or test file", path)
+		}
+	}
+	sort.Strings(found)
+	return found, nil
+}
+
+// LoadFixture 读取测试用例文件。.test.js 文件的最后一个表达式为与 JSON 格式相同的对象，
+// 可以用注释与代码生成数据，执行超过 timeout 时中断；不含 cases 的文件视为只有一个用例
+func LoadFixture(path string, timeout time.Duration) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(path, FixtureJSSuffix) {
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		vm := goja.New()
+		ctx, stop := interruptAfter(context.Background(), vm, timeout)
+		value, err := vm.RunScript(path, string(data))
+		stop()
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("%s did not finish within %s", path, timeout)
+			}
+			return nil, scriptError(err)
+		}
+		r := &runtime{vm: vm}
+		s, err := r.stringify(value)
+		if err != nil {
+			return nil, err
+		}
+		if s == "" {
+			return nil, fmt.Errorf("%s does not evaluate to a test object", path)
+		}
+		data = []byte(s)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if fixture.Cases == nil {
+		var single Case
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		// 文件级的 mocks 已经读入 fixture.Mocks
+		single.Mocks = nil
+		fixture.Cases = []Case{single}
+	}
+	for i := range fixture.Cases {
+		if fixture.Cases[i].Name == "" {
+			fixture.Cases[i].Name = fmt.Sprintf("case %d", i+1)
+		}
+	}
+	fixture.Path = path
+	return &fixture, nil
+}
+
+// Executor 执行用例文件 f 中的一个用例，src 为被测脚本；Result.Entities 为 nil 时不检查 ExpectEntities
+type Executor func(ctx context.Context, f *Fixture, src []byte, c *Case, params map[string]interface{}) (*Result, error)
+
+// LocalExecutor 在本地运行时中执行用例，使用合并后的模拟数据
+func LocalExecutor(timeout time.Duration) Executor {
+	return func(ctx context.Context, f *Fixture, src []byte, c *Case, params map[string]interface{}) (*Result, error) {
+		return Run(ctx, filepath.ToSlash(f.ScriptPath()), src, Options{
+			Params:  params,
+			Mocks:   MergeMocks(f.Mocks, c.Mocks),
+			Timeout: timeout,
+		})
+	}
+}
+
+// CaseResult 一个用例的执行结果
+type CaseResult struct {
+	Name     string
+	Duration time.Duration
+	// Error 脚本未按预期执行的原因：抛出了未预期的异常，或期望的异常没有发生
+	Error string
+	// Changes 期望（-）与实际结果（+）之间的差异，返回值的路径以 result 开头，实体数据以 entities 开头
+	Changes []sync.JSONChange
+	Logs    []LogEntry
+}
+
+// Passed 判断用例是否通过
+func (r *CaseResult) Passed() bool {
+	return r.Error == "" && len(r.Changes) == 0
+}
+
+// RunCases 依次执行用例文件中名称包含 filter 的用例
+func RunCases(ctx context.Context, f *Fixture, src []byte, filter string, exec Executor) []CaseResult {
+	specs := ParseParams(src)
+	var results []CaseResult
+	for i := range f.Cases {
+		c := &f.Cases[i]
+		if filter != "" && !strings.Contains(c.Name, filter) {
+			continue
+		}
+		results = append(results, runCase(ctx, f, c, src, specs, exec))
+	}
+	return results
+}
+
+func runCase(ctx context.Context, f *Fixture, c *Case, src []byte, specs []ParamSpec, exec Executor) CaseResult {
+	cr := CaseResult{Name: c.Name}
+
+	params := make(map[string]interface{}, len(c.Params))
+	for k, v := range c.Params {
+		params[k] = v
+	}
+	if _, err := ApplyDefaults(specs, params); err != nil {
+		cr.Error = err.Error()
+		return cr
+	}
+
+	start := time.Now()
+	result, err := exec(ctx, f, src, c, params)
+	cr.Duration = time.Since(start)
+	if result != nil {
+		cr.Logs = result.Logs
+	}
+
+	switch {
+	case c.ExpectError != "" && err == nil:
+		cr.Error = fmt.Sprintf("expected an error containing %q, but the script returned normally", c.ExpectError)
+		return cr
+	case c.ExpectError != "":
+		if !strings.Contains(err.Error(), c.ExpectError) {
+			cr.Error = fmt.Sprintf("expected an error containing %q, got: %v", c.ExpectError, err)
+		}
+		return cr
+	case err != nil:
+		cr.Error = err.Error()
+		return cr
+	}
+
+	changes, err := compareResult(c, result)
+	if err != nil {
+		cr.Error = err.Error()
+		return cr
+	}
+	cr.Changes = changes
+	return cr
+}
+
+// compareResult 把期望与实际结果放入同一个文档中比较，差异路径形如 result.data.total、entities.platform_user[id=1].name
+func compareResult(c *Case, result *Result) ([]sync.JSONChange, error) {
+	expected := make(map[string]interface{})
+	actual := make(map[string]interface{})
+
+	if len(c.Expect) > 0 {
+		var expect interface{}
+		if err := json.Unmarshal(c.Expect, &expect); err != nil {
+			return nil, fmt.Errorf("invalid expect: %w", err)
+		}
+		expected["result"] = expect
+		actual["result"] = shape(result.Value, expect, c.Exact)
+	}
+
+	if len(c.ExpectEntities) > 0 && result.Entities != nil {
+		want := make(map[string]interface{})
+		got := make(map[string]interface{})
+		for name, rows := range c.ExpectEntities {
+			expectRows, actualRows := normalize(rows), normalize(result.Entities[tableKey(name)])
+			want[name] = expectRows
+			got[name] = shape(actualRows, expectRows, c.Exact)
+		}
+		expected["entities"] = want
+		actual["entities"] = got
+	}
+
+	if len(expected) == 0 {
+		return nil, nil
+	}
+	a, err := json.Marshal(expected)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(actual)
+	if err != nil {
+		return nil, err
+	}
+	return sync.DiffJSON(a, b)
+}
+
+// shape 非 exact 时去掉实际结果中期望未列出的对象字段，数组元素按 id 或下标对应
+func shape(actual, expect interface{}, exact bool) interface{} {
+	if exact {
+		return actual
+	}
+	switch e := expect.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+		shaped := make(map[string]interface{}, len(e))
+		for k, ev := range e {
+			if av, ok := a[k]; ok {
+				shaped[k] = shape(av, ev, false)
+			}
+		}
+		return shaped
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return actual
+		}
+		shaped := make([]interface{}, len(a))
+		for i, av := range a {
+			if ev, ok := counterpart(e, av, i); ok {
+				shaped[i] = shape(av, ev, false)
+			} else {
+				shaped[i] = av
+			}
+		}
+		return shaped
+	}
+	return actual
+}
+
+// counterpart 找到实际数组元素对应的期望元素：两边的对象都带 id 时按 id，期望元素不带 id 时按下标
+func counterpart(expect []interface{}, actual interface{}, i int) (interface{}, bool) {
+	id, hasID := idOf(actual)
+	if hasID {
+		for _, ev := range expect {
+			if eid, ok := idOf(ev); ok && fmt.Sprint(eid) == fmt.Sprint(id) {
+				return ev, true
+			}
+		}
+	}
+	if i >= len(expect) {
+		return nil, false
+	}
+	if _, ok := idOf(expect[i]); ok && hasID {
+		return nil, false
+	}
+	return expect[i], true
+}
+
+func idOf(v interface{}) (interface{}, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	id, ok := obj["id"]
+	return id, ok
+}
+
+// normalize 经 JSON 转换，使 Go 数值类型与 JSON 解析结果一致
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// MergeMocks 合并文件级与用例级的模拟数据，override 优先
+func MergeMocks(base, override *Mocks) *Mocks {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+
+	merged := &Mocks{
+		Entities: make(map[string][]map[string]interface{}),
+		Session:  make(map[string]interface{}),
+		Cache:    make(map[string]interface{}),
+		// 查询与 HTTP 按顺序匹配，用例中的放在前面
+		Queries: append(append([]QueryMock{}, override.Queries...), base.Queries...),
+		HTTP:    append(append([]HTTPMock{}, override.HTTP...), base.HTTP...),
+	}
+	for _, m := range []*Mocks{base, override} {
+		for k, v := range m.Entities {
+			merged.Entities[k] = v
+		}
+		for k, v := range m.Session {
+			merged.Session[k] = v
+		}
+		for k, v := range m.Cache {
+			merged.Cache[k] = v
+		}
+	}
+	return merged
+}
@@ -0,0 +1,70 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCasesArrayPairing(t *testing.T) {
+	const src = `({ data: [{ id: 1, name: "a", age: 10 }, { id: 2, name: "b", age: 20 }] })`
+
+	tests := []struct {
+		name   string
+		expect string
+		pass   bool
+	}{
+		{"expected rows without id pair by index", `{"data":[{"name":"a"},{"name":"b"}]}`, true},
+		{"expected rows without id detect a wrong value", `{"data":[{"name":"a"},{"name":"x"}]}`, false},
+		{"expected rows with id pair by id", `{"data":[{"id":1,"age":10},{"id":2,"age":20}]}`, true},
+		{"expected rows with id detect a wrong value", `{"data":[{"id":1,"age":10},{"id":2,"age":21}]}`, false},
+		{"mixed expected rows", `{"data":[{"name":"a"},{"id":2,"name":"b"}]}`, true},
+		{"missing row", `{"data":[{"name":"a"}]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Fixture{
+				Path:  "list.api.test.json",
+				Cases: []Case{{Name: tt.name, Expect: json.RawMessage(tt.expect)}},
+			}
+			results := RunCases(context.Background(), f, []byte(src), "", LocalExecutor(time.Second))
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			r := results[0]
+			if r.Error != "" {
+				t.Fatalf("case error: %s", r.Error)
+			}
+			if r.Passed() != tt.pass {
+				t.Errorf("passed = %v, want %v; changes:\n%s", r.Passed(), tt.pass, FormatChanges(r.Changes))
+			}
+		})
+	}
+}
+
+func TestLoadFixtureTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loop.api.test.js")
+	if err := os.WriteFile(path, []byte("while (true) {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := LoadFixture(path, 50*time.Millisecond)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "did not finish") {
+			t.Fatalf("err = %v, want a timeout error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LoadFixture did not stop the script")
+	}
+}
@@ -0,0 +1,121 @@
+package script
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/geelato/cli/internal/sync"
+)
+
+// SuiteResult 一个测试用例文件的执行结果，Error 非空时文件无法加载或被测脚本无法读取
+type SuiteResult struct {
+	Fixture string
+	API     string
+	Error   string
+	Cases   []CaseResult
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+	Error    *junitError `xml:"error,omitempty"`
+}
+
+type junitCase struct {
+	Name      string      `xml:"name,attr"`
+	ClassName string      `xml:"classname,attr"`
+	Time      string      `xml:"time,attr"`
+	Failure   *junitError `xml:"failure,omitempty"`
+	SystemOut string      `xml:"system-out,omitempty"`
+}
+
+type junitError struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit 以 JUnit XML 格式写出测试结果：每个用例文件一个 testsuite，无法加载的文件记为 error
+func WriteJUnit(path string, suites []SuiteResult) error {
+	report := junitSuites{Name: "geelato api test"}
+	var total time.Duration
+
+	for _, s := range suites {
+		suite := junitSuite{Name: s.Fixture}
+		if s.Error != "" {
+			suite.Errors = 1
+			suite.Error = &junitError{Message: s.Error}
+		}
+
+		var elapsed time.Duration
+		for _, c := range s.Cases {
+			elapsed += c.Duration
+			jc := junitCase{
+				Name:      c.Name,
+				ClassName: s.API,
+				Time:      seconds(c.Duration),
+				SystemOut: formatLogs(c.Logs),
+			}
+			if !c.Passed() {
+				suite.Failures++
+				jc.Failure = &junitError{Message: c.Error, Text: FormatChanges(c.Changes)}
+				if c.Error == "" {
+					jc.Failure.Message = "result does not match the expectation"
+				}
+			}
+			suite.Cases = append(suite.Cases, jc)
+		}
+		suite.Tests = len(suite.Cases)
+		suite.Time = seconds(elapsed)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		total += elapsed
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = seconds(total)
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
+
+// FormatChanges 以纯文本输出差异，每行一处
+func FormatChanges(changes []sync.JSONChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatLogs(logs []LogEntry) string {
+	lines := make([]string, 0, len(logs))
+	for _, l := range logs {
+		lines = append(lines, fmt.Sprintf("[%s] %s", l.Level, l.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
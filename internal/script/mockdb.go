@@ -0,0 +1,590 @@
+package script
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mockDB $db 的本地实现：在模拟的实体数据上执行常见的单表 SQL。
+// 支持 SELECT（列、COUNT(*)、WHERE、ORDER BY、LIMIT/OFFSET）、INSERT、UPDATE 与 DELETE，
+// WHERE 支持 =、!=、<>、>、<、>=、<=、LIKE、IN、IS [NOT] NULL 以及 AND/OR 与括号。
+// 无法执行的语句不会报错：查询返回整张表，写入不生效，并通过 warn 给出提示
+type mockDB struct {
+	entities map[string][]map[string]interface{}
+	queries  []QueryMock
+	warn     func(format string, args ...interface{})
+}
+
+var (
+	selectStmt = regexp.MustCompile(`(?is)^select\s+(.+?)\s+from\s+([\w.]+)(?:\s+(?:as\s+)?\w+)?(?:\s+where\s+(.+?))?(?:\s+order\s+by\s+(.+?))?(?:\s+limit\s+(\S+?)(?:\s*,\s*(\S+?)|\s+offset\s+(\S+?))?)?$`)
+	insertStmt = regexp.MustCompile(`(?is)^insert\s+into\s+([\w.]+)\s*\((.+?)\)\s*values\s*\((.+)\)$`)
+	updateStmt = regexp.MustCompile(`(?is)^update\s+([\w.]+)\s+set\s+(.+?)(?:\s+where\s+(.+?))?$`)
+	deleteStmt = regexp.MustCompile(`(?is)^delete\s+from\s+([\w.]+)(?:\s+(?:as\s+)?\w+)?(?:\s+where\s+(.+?))?$`)
+
+	countColumn = regexp.MustCompile(`(?i)^count\s*\(\s*(?:\*|1|[\w.]+)\s*\)(?:\s+(?:as\s+)?(\w+))?$`)
+	plainColumn = regexp.MustCompile(`(?i)^(?:\w+\.)?(\w+|\*)(?:\s+(?:as\s+)?(\w+))?$`)
+	comparison  = regexp.MustCompile(`(?is)^(?:\w+\.)?(\w+)\s*(>=|<=|<>|!=|=|>|<|\s+not\s+like\s+|\s+like\s+|\s+not\s+in\s*|\s+in\s*)(.+)$`)
+	nullCheck   = regexp.MustCompile(`(?is)^(?:\w+\.)?(\w+)\s+is\s+(not\s+)?null$`)
+)
+
+// sqlNull 区分 SQL 中的 NULL 与列不存在
+type sqlNull struct{}
+
+func newMockDB(mocks *Mocks, warn func(string, ...interface{})) *mockDB {
+	db := &mockDB{
+		entities: make(map[string][]map[string]interface{}),
+		warn:     warn,
+	}
+	if mocks != nil {
+		for name, rows := range mocks.Entities {
+			db.entities[tableKey(name)] = copyRows(rows)
+		}
+		db.queries = mocks.Queries
+	}
+	return db
+}
+
+// Entities 返回执行后的实体数据，写入语句会修改它们
+func (db *mockDB) Entities() map[string][]map[string]interface{} {
+	return db.entities
+}
+
+// Query 执行查询语句，按顺序优先使用匹配的 QueryMock
+func (db *mockDB) Query(sql string, argv []interface{}) ([]map[string]interface{}, error) {
+	normalized := normalizeSQL(sql)
+	for _, q := range db.queries {
+		if q.matches(normalized) {
+			return copyRows(q.Result), nil
+		}
+	}
+
+	stmt := bindArgs(normalized)
+	m := selectStmt.FindStringSubmatch(stmt)
+	if m == nil {
+		db.warn("$db.query: unsupported statement, returning no rows: %s", sql)
+		return []map[string]interface{}{}, nil
+	}
+
+	table, ok := db.entities[tableKey(m[2])]
+	if !ok {
+		db.warn("$db.query: no mock data for entity %s, treating it as empty", m[2])
+	}
+
+	rows, err := filterRows(table, m[3], argv)
+	if err != nil {
+		db.warn("$db.query: %v, returning all rows of %s; mock this query in \"queries\" for exact results", err, m[2])
+		return copyRows(table), nil
+	}
+
+	if m[4] != "" {
+		if err := sortRows(rows, m[4]); err != nil {
+			db.warn("$db.query: %v, rows are not sorted", err)
+		}
+	}
+
+	if m[5] != "" {
+		limit, offset := m[5], m[7]
+		if m[6] != "" {
+			// MySQL 的 LIMIT offset, count
+			limit, offset = m[6], m[5]
+		}
+		rows = limitRows(rows, intValue(value(limit, argv)), intValue(value(offset, argv)))
+	}
+
+	projected, err := projectRows(rows, m[1])
+	if err != nil {
+		db.warn("$db.query: %v, returning whole rows", err)
+		return rows, nil
+	}
+	return projected, nil
+}
+
+// Execute 执行写入语句，返回影响的行数
+func (db *mockDB) Execute(sql string, argv []interface{}) (int, error) {
+	stmt := bindArgs(normalizeSQL(sql))
+
+	if m := insertStmt.FindStringSubmatch(stmt); m != nil {
+		columns := splitTop(m[2], ",")
+		values := splitTop(m[3], ",")
+		if len(columns) != len(values) {
+			return 0, fmt.Errorf("INSERT has %d columns but %d values", len(columns), len(values))
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			row[columnName(col)] = storeValue(value(values[i], argv))
+		}
+		key := tableKey(m[1])
+		db.entities[key] = append(db.entities[key], row)
+		return 1, nil
+	}
+
+	if m := updateStmt.FindStringSubmatch(stmt); m != nil {
+		table := db.table(m[1])
+		if table == nil {
+			db.warn("$db.execute: no mock data for entity %s, nothing updated", m[1])
+			return 0, nil
+		}
+		set := make(map[string]interface{})
+		for _, assign := range splitTop(m[2], ",") {
+			parts := strings.SplitN(assign, "=", 2)
+			if len(parts) != 2 {
+				return 0, fmt.Errorf("unsupported assignment %q", assign)
+			}
+			set[columnName(parts[0])] = storeValue(value(parts[1], argv))
+		}
+		count := 0
+		for _, row := range table {
+			ok, err := matchRow(row, m[3], argv)
+			if err != nil {
+				db.warn("$db.execute: %v, nothing updated", err)
+				return 0, nil
+			}
+			if ok {
+				for k, v := range set {
+					row[k] = v
+				}
+				count++
+			}
+		}
+		return count, nil
+	}
+
+	if m := deleteStmt.FindStringSubmatch(stmt); m != nil {
+		table := db.table(m[1])
+		if table == nil {
+			return 0, nil
+		}
+		kept := table[:0]
+		for _, row := range table {
+			ok, err := matchRow(row, m[2], argv)
+			if err != nil {
+				db.warn("$db.execute: %v, nothing deleted", err)
+				return 0, nil
+			}
+			if !ok {
+				kept = append(kept, row)
+			}
+		}
+		db.entities[tableKey(m[1])] = kept
+		return len(table) - len(kept), nil
+	}
+
+	db.warn("$db.execute: unsupported statement, ignored: %s", sql)
+	return 0, nil
+}
+
+func (db *mockDB) table(name string) []map[string]interface{} {
+	return db.entities[tableKey(name)]
+}
+
+// tableKey 实体数据按小写表名保存，忽略库名前缀
+func tableKey(name string) string {
+	return strings.ToLower(columnName(name))
+}
+
+func (q QueryMock) matches(normalized string) bool {
+	return q.SQL != "" && strings.Contains(strings.ToLower(normalized), strings.ToLower(normalizeSQL(q.SQL)))
+}
+
+// normalizeSQL 合并空白、去掉反引号与结尾的分号
+func normalizeSQL(sql string) string {
+	sql = strings.ReplaceAll(sql, "`", "")
+	sql = strings.Join(strings.Fields(sql), " ")
+	return strings.TrimSuffix(sql, ";")
+}
+
+// bindArgs 把引号外的 ? 依次替换为 :0、:1…，以便拆分语句后仍能找到对应的参数
+func bindArgs(sql string) string {
+	var b strings.Builder
+	n := 0
+	var quote rune
+	for _, r := range sql {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			fmt.Fprintf(&b, ":%d", n)
+			n++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// value 解析语句中的值：参数占位符、字符串、数字、NULL 与布尔值
+func value(expr string, argv []interface{}) interface{} {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "":
+		return nil
+	case strings.HasPrefix(expr, ":"):
+		if i, err := strconv.Atoi(expr[1:]); err == nil {
+			if i < len(argv) {
+				if argv[i] == nil {
+					return sqlNull{}
+				}
+				return argv[i]
+			}
+			return sqlNull{}
+		}
+	case len(expr) >= 2 && (expr[0] == '\'' || expr[0] == '"') && expr[len(expr)-1] == expr[0]:
+		return expr[1 : len(expr)-1]
+	case strings.EqualFold(expr, "null"):
+		return sqlNull{}
+	case strings.EqualFold(expr, "true"):
+		return true
+	case strings.EqualFold(expr, "false"):
+		return false
+	}
+	if f, err := strconv.ParseFloat(expr, 64); err == nil {
+		return f
+	}
+	return expr
+}
+
+func storeValue(v interface{}) interface{} {
+	if _, ok := v.(sqlNull); ok {
+		return nil
+	}
+	return v
+}
+
+func filterRows(table []map[string]interface{}, where string, argv []interface{}) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0, len(table))
+	for _, row := range table {
+		ok, err := matchRow(row, where, argv)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rows = append(rows, copyRow(row))
+		}
+	}
+	return rows, nil
+}
+
+// matchRow 计算 WHERE 条件，where 为空时匹配所有行
+func matchRow(row map[string]interface{}, where string, argv []interface{}) (bool, error) {
+	where = strings.TrimSpace(where)
+	if where == "" {
+		return true, nil
+	}
+	for isWrapped(where) {
+		where = strings.TrimSpace(where[1 : len(where)-1])
+	}
+
+	if parts := splitTop(where, " or "); len(parts) > 1 {
+		for _, p := range parts {
+			ok, err := matchRow(row, p, argv)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+	if parts := splitTop(where, " and "); len(parts) > 1 {
+		for _, p := range parts {
+			ok, err := matchRow(row, p, argv)
+			if err != nil || !ok {
+				return ok, err
+			}
+		}
+		return true, nil
+	}
+
+	if m := nullCheck.FindStringSubmatch(where); m != nil {
+		isNull := row[m[1]] == nil
+		return isNull == (m[2] == ""), nil
+	}
+
+	m := comparison.FindStringSubmatch(where)
+	if m == nil {
+		return false, fmt.Errorf("cannot evaluate condition %q", where)
+	}
+	left := row[m[1]]
+	op := strings.ToLower(strings.Join(strings.Fields(m[2]), " "))
+
+	switch op {
+	case "like", "not like":
+		pattern, ok := value(m[3], argv).(string)
+		if !ok {
+			return false, fmt.Errorf("LIKE needs a string pattern in %q", where)
+		}
+		matched := likePattern(pattern).MatchString(fmt.Sprint(left))
+		return matched == (op == "like"), nil
+	case "in", "not in":
+		list := strings.TrimSpace(m[3])
+		if !isWrapped(list) {
+			return false, fmt.Errorf("cannot evaluate condition %q", where)
+		}
+		found := false
+		for _, item := range splitTop(list[1:len(list)-1], ",") {
+			if c, ok := compare(left, value(item, argv)); ok && c == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (op == "in"), nil
+	}
+
+	c, ok := compare(left, value(m[3], argv))
+	if !ok {
+		// 与 NULL 比较的结果在 SQL 中为未知，不匹配任何行
+		return false, nil
+	}
+	switch op {
+	case "=":
+		return c == 0, nil
+	case "!=", "<>":
+		return c != 0, nil
+	case ">":
+		return c > 0, nil
+	case "<":
+		return c < 0, nil
+	case ">=":
+		return c >= 0, nil
+	default:
+		return c <= 0, nil
+	}
+}
+
+// compare 比较两个值：能转换为数字时按数字比较，否则按字符串比较；任一侧为 NULL 时返回 false
+func compare(a, b interface{}) (int, bool) {
+	if _, ok := b.(sqlNull); ok || a == nil {
+		return 0, false
+	}
+	if fa, ok := number(a); ok {
+		if fb, ok := number(b); ok {
+			switch {
+			case fa < fb:
+				return -1, true
+			case fa > fb:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)), true
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func intValue(v interface{}) int {
+	f, _ := number(v)
+	return int(f)
+}
+
+// likePattern 把 LIKE 模式转换为不区分大小写的正则表达式
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func sortRows(rows []map[string]interface{}, orderBy string) error {
+	type key struct {
+		column string
+		desc   bool
+	}
+	var keys []key
+	for _, part := range splitTop(orderBy, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("cannot evaluate ORDER BY %q", orderBy)
+		}
+		k := key{column: columnName(fields[0])}
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "desc":
+				k.desc = true
+			case "asc":
+			default:
+				return fmt.Errorf("cannot evaluate ORDER BY %q", orderBy)
+			}
+		}
+		keys = append(keys, k)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			a, b := rows[i][k.column], rows[j][k.column]
+			// NULL 排在最前，与 MySQL 升序一致
+			if a == nil || b == nil {
+				if (a == nil) == (b == nil) {
+					continue
+				}
+				return (a == nil) != k.desc
+			}
+			c, _ := compare(a, b)
+			if c != 0 {
+				return (c < 0) != k.desc
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+func limitRows(rows []map[string]interface{}, limit, offset int) []map[string]interface{} {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(rows) {
+		return []map[string]interface{}{}
+	}
+	rows = rows[offset:]
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// projectRows 按 SELECT 的列返回结果，只有 COUNT(*) 一列时返回计数
+func projectRows(rows []map[string]interface{}, columns string) ([]map[string]interface{}, error) {
+	cols := splitTop(columns, ",")
+	if len(cols) == 1 {
+		if m := countColumn.FindStringSubmatch(cols[0]); m != nil {
+			alias := m[1]
+			if alias == "" {
+				alias = cols[0]
+			}
+			return []map[string]interface{}{{alias: len(rows)}}, nil
+		}
+	}
+
+	type column struct{ name, alias string }
+	var selected []column
+	for _, c := range cols {
+		m := plainColumn.FindStringSubmatch(c)
+		if m == nil {
+			return nil, fmt.Errorf("unsupported column %q", c)
+		}
+		if m[1] == "*" {
+			return rows, nil
+		}
+		alias := m[2]
+		if alias == "" {
+			alias = m[1]
+		}
+		selected = append(selected, column{m[1], alias})
+	}
+
+	result := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		projected := make(map[string]interface{}, len(selected))
+		for _, c := range selected {
+			projected[c.alias] = row[c.name]
+		}
+		result = append(result, projected)
+	}
+	return result, nil
+}
+
+// splitTop 按分隔符拆分，忽略括号与引号内的分隔符；分隔符不区分大小写
+func splitTop(s, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	lower := strings.ToLower(s)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(lower[i:], sep):
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// isWrapped 判断表达式是否整体被一对括号包住
+func isWrapped(s string) bool {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return false
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(s)-1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func columnName(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "."); i >= 0 {
+		s = s[i+1:]
+	}
+	return s
+}
+
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyRows(rows []map[string]interface{}) []map[string]interface{} {
+	copied := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		copied = append(copied, copyRow(row))
+	}
+	return copied
+}
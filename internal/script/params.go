@@ -0,0 +1,136 @@
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParamSpec API 脚本中 // @param 块声明的参数
+type ParamSpec struct {
+	Name        string
+	Type        string
+	Required    bool
+	Default     string
+	Description string
+}
+
+// ParseParams 读取脚本中的 // @param 块，每块由紧随其后的 // key: value 行组成
+func ParseParams(src []byte) []ParamSpec {
+	var specs []ParamSpec
+	var current *ParamSpec
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "//") {
+			current = nil
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "//"))
+		if line == "@param" {
+			specs = append(specs, ParamSpec{})
+			current = &specs[len(specs)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			current = nil
+			continue
+		}
+		val = strings.TrimSpace(val)
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.Name = val
+		case "type":
+			current.Type = val
+		case "required":
+			current.Required = val == "true"
+		case "default":
+			current.Default = val
+		case "description":
+			current.Description = val
+		}
+	}
+
+	named := specs[:0]
+	for _, spec := range specs {
+		if spec.Name != "" {
+			named = append(named, spec)
+		}
+	}
+	return named
+}
+
+// ParseParamArgs 把 key=value 形式的参数按声明的类型转换后写入 params，未声明的参数保留为字符串
+func ParseParamArgs(specs []ParamSpec, args []string, params map[string]interface{}) error {
+	for _, arg := range args {
+		key, val, ok := strings.Cut(arg, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid parameter %q, expected key=value", arg)
+		}
+		v, err := convertParam(findSpec(specs, key), val)
+		if err != nil {
+			return fmt.Errorf("invalid parameter %s: %w", key, err)
+		}
+		params[key] = v
+	}
+	return nil
+}
+
+// ApplyDefaults 为缺失的参数填入 default，返回仍然缺失的必填参数
+func ApplyDefaults(specs []ParamSpec, params map[string]interface{}) ([]string, error) {
+	var missing []string
+	for i := range specs {
+		spec := &specs[i]
+		if _, ok := params[spec.Name]; ok {
+			continue
+		}
+		if spec.Default != "" {
+			v, err := convertParam(spec, spec.Default)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default of parameter %s: %w", spec.Name, err)
+			}
+			params[spec.Name] = v
+			continue
+		}
+		if spec.Required {
+			missing = append(missing, spec.Name)
+		}
+	}
+	return missing, nil
+}
+
+func findSpec(specs []ParamSpec, name string) *ParamSpec {
+	for i := range specs {
+		if specs[i].Name == name {
+			return &specs[i]
+		}
+	}
+	return nil
+}
+
+// convertParam 按 Integer、Number、Boolean、Object、Array 等声明的类型转换字符串值
+func convertParam(spec *ParamSpec, raw string) (interface{}, error) {
+	if spec == nil {
+		return raw, nil
+	}
+	switch strings.ToLower(spec.Type) {
+	case "integer", "int", "long":
+		return strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	case "number", "float", "double", "decimal":
+		return strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	case "boolean", "bool":
+		return strconv.ParseBool(strings.TrimSpace(raw))
+	case "object", "array", "json", "map", "list":
+		var v interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("expected JSON for type %s: %w", spec.Type, err)
+		}
+		return v, nil
+	}
+	return raw, nil
+}
@@ -0,0 +1,410 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// DefaultTimeout 脚本默认的最长执行时间
+const DefaultTimeout = 30 * time.Second
+
+// Mocks 本地运行时使用的模拟数据，代替平台上的数据库、会话、缓存与外部 HTTP 服务
+type Mocks struct {
+	// Entities 实体（表）名到记录的映射，$db 在其上执行 SQL
+	Entities map[string][]map[string]interface{} `json:"entities,omitempty"`
+	// Queries 按 SQL 片段匹配的查询结果，优先于 Entities
+	Queries []QueryMock `json:"queries,omitempty"`
+	// Session $session 中的数据，例如当前用户的 userId
+	Session map[string]interface{} `json:"session,omitempty"`
+	// Cache $cache 的初始内容
+	Cache map[string]interface{} `json:"cache,omitempty"`
+	// HTTP $http 请求的模拟响应，未匹配的请求抛出异常
+	HTTP []HTTPMock `json:"http,omitempty"`
+}
+
+// QueryMock SQL 包含 SQL 片段（忽略大小写与空白差异）时，$db.query 直接返回 Result
+type QueryMock struct {
+	SQL    string                   `json:"sql"`
+	Result []map[string]interface{} `json:"result"`
+}
+
+// HTTPMock $http 请求的模拟响应，URL 以 * 结尾时按前缀匹配，Method 为空时匹配任意方法
+type HTTPMock struct {
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Data    interface{}       `json:"data,omitempty"`
+}
+
+// LoadMocks 读取 JSON 格式的模拟数据文件
+func LoadMocks(path string) (*Mocks, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var mocks Mocks
+	if err := json.Unmarshal(data, &mocks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &mocks, nil
+}
+
+// Options 脚本运行参数
+type Options struct {
+	// Params 请求参数，即脚本中的 $params
+	Params map[string]interface{}
+	Mocks  *Mocks
+	// Log 接收脚本通过 $log、console 输出的日志以及运行时的提示，为 nil 时只记录在 Result 中
+	Log     func(entry LogEntry)
+	Timeout time.Duration
+}
+
+// LogEntry 一条日志，Level 为 debug、info、warn 或 error
+type LogEntry struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// Statement 脚本通过 $db 执行的一条 SQL
+type Statement struct {
+	SQL  string        `json:"sql"`
+	Args []interface{} `json:"args,omitempty"`
+}
+
+// Result 脚本运行结果
+type Result struct {
+	// Value 脚本最后一个表达式的值（通常是外层函数的返回值）按 JSON 序列化后的结果
+	Value      interface{}
+	Logs       []LogEntry
+	Statements []Statement
+	// Entities 运行后的实体数据，包含脚本写入的修改
+	Entities map[string][]map[string]interface{}
+}
+
+// runtime 一次脚本运行的状态
+type runtime struct {
+	vm     *goja.Runtime
+	opts   Options
+	db     *mockDB
+	cache  map[string]interface{}
+	result *Result
+}
+
+// Run 在内置的 JavaScript 引擎中运行 API 脚本，提供 $params、$db、$session、$cache、$http、$log 与 console。
+// 这些对象只访问 opts.Mocks 中的模拟数据，不会连接数据库或发出网络请求
+func Run(ctx context.Context, name string, src []byte, opts Options) (*Result, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	mocks := opts.Mocks
+	if mocks == nil {
+		mocks = &Mocks{}
+	}
+
+	r := &runtime{
+		vm:     goja.New(),
+		opts:   opts,
+		cache:  make(map[string]interface{}),
+		result: &Result{},
+	}
+	r.db = newMockDB(mocks, func(format string, args ...interface{}) {
+		r.log("warn", fmt.Sprintf(format, args...))
+	})
+	for k, v := range mocks.Cache {
+		r.cache[k] = v
+	}
+	if err := r.install(mocks); err != nil {
+		return nil, err
+	}
+
+	ctx, stop := interruptAfter(ctx, r.vm, opts.Timeout)
+	defer stop()
+
+	value, err := r.vm.RunScript(name, string(src))
+	if err == nil {
+		value, err = r.settle(value)
+	}
+	r.result.Entities = r.db.Entities()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return r.result, fmt.Errorf("script did not finish within %s", opts.Timeout)
+		}
+		return r.result, scriptError(err)
+	}
+
+	r.result.Value, err = r.export(value)
+	if err != nil {
+		return r.result, fmt.Errorf("failed to convert the script result to JSON: %w", err)
+	}
+	return r.result, nil
+}
+
+// interruptAfter 在 timeout 到期或 ctx 取消时中断 vm 中正在执行的脚本，脚本结束后调用 stop
+func interruptAfter(ctx context.Context, vm *goja.Runtime, timeout time.Duration) (context.Context, func()) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			vm.Interrupt(ctx.Err())
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		close(done)
+		cancel()
+	}
+}
+
+// settle 脚本返回 Promise 时取其结果
+func (r *runtime) settle(value goja.Value) (goja.Value, error) {
+	p, ok := value.Export().(*goja.Promise)
+	if !ok {
+		return value, nil
+	}
+	switch p.State() {
+	case goja.PromiseStateFulfilled:
+		return p.Result(), nil
+	case goja.PromiseStateRejected:
+		return nil, fmt.Errorf("script promise rejected: %s", p.Result())
+	}
+	return nil, fmt.Errorf("script returned a promise that never settled")
+}
+
+// scriptError 去掉引擎的类型前缀，保留异常信息与位置
+func scriptError(err error) error {
+	if e, ok := err.(*goja.Exception); ok {
+		return fmt.Errorf("script error: %s", strings.TrimSpace(e.String()))
+	}
+	return err
+}
+
+func (r *runtime) install(mocks *Mocks) error {
+	params := r.opts.Params
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	globals := map[string]interface{}{
+		"$params":  r.toJS(params),
+		"$db":      r.dbObject(),
+		"$session": r.sessionObject(mocks.Session),
+		"$cache":   r.cacheObject(),
+		"$http":    r.httpObject(mocks.HTTP),
+		"$log":     r.logObject(map[string]string{"debug": "debug", "info": "info", "warn": "warn", "error": "error"}),
+		"console":  r.logObject(map[string]string{"log": "info", "debug": "debug", "info": "info", "warn": "warn", "error": "error"}),
+	}
+	for name, v := range globals {
+		if err := r.vm.Set(name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *runtime) dbObject() *goja.Object {
+	obj := r.vm.NewObject()
+	obj.Set("query", func(call goja.FunctionCall) goja.Value {
+		sql, args := r.statement(call)
+		rows, err := r.db.Query(sql, args)
+		if err != nil {
+			panic(r.vm.NewGoError(err))
+		}
+		return r.toJS(rows)
+	})
+	obj.Set("execute", func(call goja.FunctionCall) goja.Value {
+		sql, args := r.statement(call)
+		n, err := r.db.Execute(sql, args)
+		if err != nil {
+			panic(r.vm.NewGoError(err))
+		}
+		return r.vm.ToValue(n)
+	})
+	return obj
+}
+
+// statement 读取 $db 方法的 SQL 与参数数组并记录，单个非数组参数视为只有一个参数
+func (r *runtime) statement(call goja.FunctionCall) (string, []interface{}) {
+	sql := call.Argument(0).String()
+	var args []interface{}
+	if arg := call.Argument(1); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+		if list, ok := arg.Export().([]interface{}); ok {
+			args = list
+		} else {
+			args = []interface{}{arg.Export()}
+		}
+	}
+	r.result.Statements = append(r.result.Statements, Statement{SQL: normalizeSQL(sql), Args: args})
+	return sql, args
+}
+
+// sessionObject $session 带有模拟会话中的字段，get/set 读写同一个对象
+func (r *runtime) sessionObject(session map[string]interface{}) *goja.Object {
+	if session == nil {
+		session = map[string]interface{}{}
+	}
+	obj := r.toJS(session).ToObject(r.vm)
+	obj.Set("get", func(key string) goja.Value {
+		return orNull(obj.Get(key))
+	})
+	obj.Set("set", func(key string, v goja.Value) {
+		obj.Set(key, v)
+	})
+	return obj
+}
+
+func (r *runtime) cacheObject() *goja.Object {
+	obj := r.vm.NewObject()
+	obj.Set("get", func(key string) goja.Value {
+		v, ok := r.cache[key]
+		if !ok {
+			return goja.Null()
+		}
+		return r.toJS(v)
+	})
+	// set 的第三个参数为过期秒数，本地运行时忽略
+	obj.Set("set", func(key string, v goja.Value) {
+		exported, err := r.export(v)
+		if err != nil {
+			panic(r.vm.NewGoError(err))
+		}
+		r.cache[key] = exported
+	})
+	remove := func(key string) {
+		delete(r.cache, key)
+	}
+	obj.Set("remove", remove)
+	obj.Set("delete", remove)
+	return obj
+}
+
+func (r *runtime) httpObject(mocks []HTTPMock) *goja.Object {
+	obj := r.vm.NewObject()
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+		method := method
+		obj.Set(strings.ToLower(method), func(call goja.FunctionCall) goja.Value {
+			url := call.Argument(0).String()
+			for _, m := range mocks {
+				if m.matches(method, url) {
+					status, headers := m.Status, m.Headers
+					if status == 0 {
+						status = 200
+					}
+					if headers == nil {
+						headers = map[string]string{}
+					}
+					return r.toJS(map[string]interface{}{
+						"status":  status,
+						"headers": headers,
+						"data":    m.Data,
+					})
+				}
+			}
+			panic(r.vm.NewGoError(fmt.Errorf("$http.%s %s: no mocked response", strings.ToLower(method), url)))
+		})
+	}
+	return obj
+}
+
+func (m HTTPMock) matches(method, url string) bool {
+	if m.Method != "" && !strings.EqualFold(m.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(m.URL, "*"); ok {
+		return strings.HasPrefix(url, prefix)
+	}
+	return m.URL == url
+}
+
+// logObject 创建日志对象，methods 为方法名到日志级别的映射
+func (r *runtime) logObject(methods map[string]string) *goja.Object {
+	obj := r.vm.NewObject()
+	for name, level := range methods {
+		level := level
+		obj.Set(name, func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, 0, len(call.Arguments))
+			for _, arg := range call.Arguments {
+				parts = append(parts, r.format(arg))
+			}
+			r.log(level, strings.Join(parts, " "))
+			return goja.Undefined()
+		})
+	}
+	return obj
+}
+
+func (r *runtime) log(level, message string) {
+	entry := LogEntry{Level: level, Message: message}
+	r.result.Logs = append(r.result.Logs, entry)
+	if r.opts.Log != nil {
+		r.opts.Log(entry)
+	}
+}
+
+// format 字符串原样输出，其余值按 JSON 输出
+func (r *runtime) format(v goja.Value) string {
+	if s, ok := v.Export().(string); ok {
+		return s
+	}
+	if goja.IsUndefined(v) {
+		return "undefined"
+	}
+	if s, err := r.stringify(v); err == nil && s != "" {
+		return s
+	}
+	return v.String()
+}
+
+// toJS 经 JSON 转换为原生的 JavaScript 对象与数组，脚本可以正常使用数组方法
+func (r *runtime) toJS(v interface{}) goja.Value {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(r.vm.NewGoError(err))
+	}
+	parse, _ := goja.AssertFunction(r.vm.Get("JSON").ToObject(r.vm).Get("parse"))
+	value, err := parse(goja.Undefined(), r.vm.ToValue(string(data)))
+	if err != nil {
+		panic(r.vm.NewGoError(err))
+	}
+	return value
+}
+
+// export 按 JSON.stringify 的规则把 JavaScript 值转换为 Go 值，与平台返回给客户端的结果一致
+func (r *runtime) export(v goja.Value) (interface{}, error) {
+	s, err := r.stringify(v)
+	if err != nil || s == "" {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal([]byte(s), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// stringify 调用 JSON.stringify，值无法序列化（如 undefined）时返回空字符串
+func (r *runtime) stringify(v goja.Value) (string, error) {
+	stringify, _ := goja.AssertFunction(r.vm.Get("JSON").ToObject(r.vm).Get("stringify"))
+	out, err := stringify(goja.Undefined(), v)
+	if err != nil {
+		return "", scriptError(err)
+	}
+	if goja.IsUndefined(out) {
+		return "", nil
+	}
+	return out.String(), nil
+}
+
+func orNull(v goja.Value) goja.Value {
+	if v == nil || goja.IsUndefined(v) {
+		return goja.Null()
+	}
+	return v
+}
@@ -0,0 +1,234 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/geelato/cli/internal/platform"
+	"github.com/geelato/cli/pkg/crypto"
+	gerrors "github.com/geelato/cli/pkg/errors"
+)
+
+// ListBranches 返回平台上应用的全部分支
+func (s *SyncService) ListBranches(ctx context.Context) ([]platform.Branch, error) {
+	branches, err := s.platform.ListBranches(ctx, s.pushOptions("").AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return branches, nil
+}
+
+// CreateBranch 从 from 分支创建新分支。from 为空时从当前分支创建，
+// 并以工作区所在的版本为起点，使新分支与本地内容一致
+func (s *SyncService) CreateBranch(ctx context.Context, name, from string) (*platform.Branch, error) {
+	version := ""
+	if from == "" {
+		from = s.Branch()
+		state, err := LoadState(s.cwd)
+		if err != nil {
+			return nil, err
+		}
+		version = state.Version
+	}
+
+	branch, err := s.platform.CreateBranch(ctx, s.pushOptions("").AppID, name, from, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch: %w", err)
+	}
+	return branch, nil
+}
+
+// SwitchBranch 把工作区切换到另一个分支的最新版本并记录到同步状态。
+// 与 checkout 相同，存在未推送的本地修改或未解决的冲突时拒绝执行
+func (s *SyncService) SwitchBranch(ctx context.Context, name string) (*PullResult, error) {
+	branches, err := s.ListBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *platform.Branch
+	for i := range branches {
+		if branches[i].Name == name {
+			target = &branches[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, gerrors.New(gerrors.ErrSync, fmt.Sprintf("平台上不存在分支 %s", name))
+	}
+
+	s.SetBranch(name)
+	return s.Checkout(ctx, target.Version)
+}
+
+// DeleteBranch 删除平台上的分支，不允许删除工作区当前所在的分支
+func (s *SyncService) DeleteBranch(ctx context.Context, name string) error {
+	if name == s.Branch() {
+		return gerrors.New(gerrors.ErrSync, fmt.Sprintf("不能删除当前所在的分支 %s，请先切换到其他分支", name))
+	}
+	if err := s.platform.DeleteBranch(ctx, s.pushOptions("").AppID, name); err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+	return nil
+}
+
+// BranchMergeResult 分支合并结果。合并结果只写入工作区，push 后才发布到当前分支
+type BranchMergeResult struct {
+	*PullResult
+	Source string
+	Target string
+	// Base 两个分支的共同祖先版本，为空表示没有共同历史
+	Base string
+	// UpToDate 来源分支的修改已全部包含在当前分支中
+	UpToDate bool
+}
+
+// MergeBranch 把来源分支的最新版本合并到工作区。以两个分支的共同祖先版本为基线逐文件三方合并：
+// 只有一侧修改的文件直接采用该侧内容，两侧都修改的实体、页面、工作流 JSON 按键合并，
+// API 脚本的文件头按标签合并，其余按行合并；无法自动合并的冲突交给 sync resolve 处理。
+// 要求工作区处于当前分支的最新版本且没有未推送的修改
+func (s *SyncService) MergeBranch(ctx context.Context, source string) (*BranchMergeResult, error) {
+	if err := s.checkUnresolved(); err != nil {
+		return nil, err
+	}
+
+	opts := s.pushOptions("")
+	if source == opts.Branch {
+		return nil, gerrors.New(gerrors.ErrSync, fmt.Sprintf("不能把分支 %s 合并到自身", source))
+	}
+
+	state, err := LoadState(s.cwd)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := DetectChanges(s.cwd, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect changes: %w", err)
+	}
+	if len(changes) > 0 {
+		return nil, gerrors.New(gerrors.ErrSync,
+			fmt.Sprintf("本地有 %d 个未推送的变更，请先 push 或撤销后再合并", len(changes)))
+	}
+
+	head, err := s.resolveVersion(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if head != state.Version {
+		return nil, gerrors.New(gerrors.ErrSyncVersion,
+			fmt.Sprintf("工作区版本 %s 不是分支 %s 的最新版本 %s，请先 pull", state.Version, opts.Branch, head))
+	}
+
+	targetVersions, err := s.platform.ListVersions(ctx, opts.AppID, opts.Branch, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	sourceVersions, err := s.platform.ListVersions(ctx, opts.AppID, source, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	if len(sourceVersions) == 0 {
+		return nil, gerrors.New(gerrors.ErrSyncVersion, fmt.Sprintf("分支 %s 没有任何版本", source))
+	}
+
+	result := &BranchMergeResult{
+		PullResult: &PullResult{Version: sourceVersions[0].Version},
+		Source:     source,
+		Target:     opts.Branch,
+		Base:       mergeBase(targetVersions, sourceVersions),
+	}
+	if result.Base == result.Version {
+		result.UpToDate = true
+		return result, nil
+	}
+
+	remote, err := s.fetchPackage(ctx, result.Version)
+	if err != nil {
+		return nil, err
+	}
+	base := map[string][]byte{}
+	if result.Base != "" {
+		if base, err = s.fetchPackage(ctx, result.Base); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := MergeChanges(s.cwd, base, remote, result.PullResult); err != nil {
+		return nil, fmt.Errorf("failed to merge branch: %w", err)
+	}
+
+	state.MergeFrom = result.Version
+	if err := state.Save(s.cwd); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeBase 返回来源分支历史中第一个同时出现在目标分支历史中的版本，即两个分支的共同祖先
+func mergeBase(target, source []platform.SyncStatus) string {
+	inTarget := make(map[string]bool, len(target))
+	for _, v := range target {
+		inTarget[v.Version] = true
+	}
+	for _, v := range source {
+		if inTarget[v.Version] {
+			return v.Version
+		}
+	}
+	return ""
+}
+
+// MergeChanges 以 base 为共同祖先把 remote 合并到工作区，规则与 PullChanges 相同，
+// 但不更新同步版本与基线：合并结果作为本地修改，由下一次 push 发布。冲突记录到 ConflictsFile
+func MergeChanges(root string, base, remote map[string][]byte, result *PullResult) error {
+	local, err := ScanFiles(root)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(local)+len(remote))
+	var paths []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for p := range local {
+		add(p)
+	}
+	for p := range base {
+		add(p)
+	}
+	for p := range remote {
+		add(p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		remoteData, inRemote := remote[p]
+		baseData, inBase := base[p]
+		localHash, inLocal := local[p]
+
+		v := fileVersions{
+			remoteData: remoteData,
+			inRemote:   inRemote,
+			inLocal:    inLocal,
+			localHash:  localHash,
+			inBase:     inBase,
+			readBase:   func() []byte { return baseData },
+		}
+		if inRemote {
+			v.remoteHash = crypto.SHA256String(remoteData)
+		}
+		if inBase {
+			v.baseHash = crypto.SHA256String(baseData)
+		}
+		if err := mergeFile(root, p, result, v); err != nil {
+			return err
+		}
+	}
+
+	return SaveConflicts(root, result.Conflicts)
+}
@@ -0,0 +1,224 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/geelato/cli/internal/file"
+)
+
+// ChangeType 本地文件相对上次同步的变更类型
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeModified ChangeType = "modified"
+	ChangeDeleted  ChangeType = "deleted"
+)
+
+// Change 单个文件的变更。路径相对于应用根目录，使用 / 分隔
+type Change struct {
+	Type       ChangeType
+	Path       string
+	LocalHash  string
+	RemoteHash string
+}
+
+// SkippedFile 扫描时跳过的文件或目录（目录以 / 结尾）及原因
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// ScanFiles 扫描应用目录，返回相对路径到文件哈希的映射，跳过 .geelatoignore 与默认规则忽略的路径
+func ScanFiles(root string) (map[string]string, error) {
+	files, _, err := scanFiles(root)
+	return files, err
+}
+
+// scanFiles 扫描应用目录，同时返回被跳过的目录和文件
+func scanFiles(root string) (map[string]string, []SkippedFile, error) {
+	ignore, err := file.LoadIgnore(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make(map[string]string)
+	skipped, err := hashTree(root, root, ignore, files)
+	return files, skipped, err
+}
+
+// hashTree 计算 dir 下未被忽略的文件哈希并写入 files，路径相对 root，返回被跳过的目录和文件
+func hashTree(root, dir string, ignore *file.Ignore, files map[string]string) ([]SkippedFile, error) {
+	var skipped []SkippedFile
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if rule := ignore.Match(relPath, info.IsDir()); rule != nil {
+			if info.IsDir() {
+				skipped = append(skipped, SkippedFile{Path: relPath + "/", Reason: "ignored by " + rule.String()})
+				return filepath.SkipDir
+			}
+			skipped = append(skipped, SkippedFile{Path: relPath, Reason: "ignored by " + rule.String()})
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			skipped = append(skipped, SkippedFile{Path: relPath, Reason: "not a regular file"})
+			return nil
+		}
+
+		hash, err := file.HashFile(path)
+		if err != nil {
+			return err
+		}
+		files[relPath] = hash
+		return nil
+	})
+
+	return skipped, err
+}
+
+// FileType 按应用目录结构识别文件类型：model、api、page、workflow、config，
+// 不在约定目录下的文件按扩展名识别，无法识别时为 other
+func FileType(path string) string {
+	switch {
+	case path == "geelato.json":
+		return "config"
+	case strings.HasPrefix(path, "meta/"):
+		return "model"
+	case strings.HasPrefix(path, "api/"):
+		return "api"
+	case strings.HasPrefix(path, "page/"):
+		return "page"
+	case strings.HasPrefix(path, "workflow/"):
+		return "workflow"
+	}
+
+	switch filepath.Ext(path) {
+	case ".json":
+		return "model"
+	case ".js":
+		return "api"
+	case ".xml", ".bpmn":
+		return "workflow"
+	}
+	return "other"
+}
+
+// DetectChanges 对比当前文件与同步状态，返回新增、修改和删除的文件，按路径排序。
+// 已同步过、后来被 .geelatoignore 忽略的文件不视为删除，平台上的内容保持不变
+func DetectChanges(root string, state *State) ([]Change, error) {
+	files, err := ScanFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	base, err := unignored(root, state.Files)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(base, files), nil
+}
+
+// DetectPathChanges 只对比 paths（相对应用根目录的文件或目录）下的文件与同步状态，不扫描整个应用。
+// 不存在的路径视为删除，其下已同步的文件都计为删除
+func DetectPathChanges(root string, state *State, paths []string) ([]Change, error) {
+	ignore, err := file.LoadIgnore(root)
+	if err != nil {
+		return nil, err
+	}
+
+	base := make(map[string]string)
+	current := make(map[string]string)
+	for path, hash := range state.Files {
+		if matchPaths(path, paths) && !ignore.Ignored(path, false) {
+			base[path] = hash
+		}
+	}
+
+	for _, p := range paths {
+		fullPath := filepath.Join(root, filepath.FromSlash(p))
+		info, err := os.Lstat(fullPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p != "." && ignore.Ignored(p, info.IsDir()) {
+			continue
+		}
+
+		switch {
+		case info.IsDir():
+			if _, err := hashTree(root, fullPath, ignore, current); err != nil {
+				return nil, err
+			}
+		case info.Mode().IsRegular():
+			hash, err := file.HashFile(fullPath)
+			if err != nil {
+				return nil, err
+			}
+			current[p] = hash
+		}
+	}
+
+	return Diff(base, current), nil
+}
+
+// unignored 去掉 files 中被忽略的路径
+func unignored(root string, files map[string]string) (map[string]string, error) {
+	ignore, err := file.LoadIgnore(root)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(files))
+	for path, hash := range files {
+		if !ignore.Ignored(path, false) {
+			result[path] = hash
+		}
+	}
+	return result, nil
+}
+
+// Diff 对比两组文件哈希，base 为上次同步时的状态
+func Diff(base, current map[string]string) []Change {
+	var changes []Change
+
+	for path, hash := range current {
+		lastHash, exists := base[path]
+		switch {
+		case !exists:
+			changes = append(changes, Change{Type: ChangeAdded, Path: path, LocalHash: hash})
+		case lastHash != hash:
+			changes = append(changes, Change{Type: ChangeModified, Path: path, LocalHash: hash, RemoteHash: lastHash})
+		}
+	}
+
+	for path, hash := range base {
+		if _, exists := current[path]; !exists {
+			changes = append(changes, Change{Type: ChangeDeleted, Path: path, RemoteHash: hash})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/geelato/cli/pkg/utils"
+)
+
+// ConflictsFile 记录 pull 合并时产生、尚未解决的冲突，供 sync resolve 使用
+var ConflictsFile = filepath.Join(StateDir, "conflicts.json")
+
+// ConflictKind 冲突类型
+type ConflictKind string
+
+const (
+	// ConflictContent 本地与远端修改了同一文件的相同部分
+	ConflictContent ConflictKind = "content"
+	// ConflictDeletedLocal 本地删除了文件，远端修改了它
+	ConflictDeletedLocal ConflictKind = "deleted-local"
+	// ConflictDeletedRemote 远端删除了文件，本地修改了它
+	ConflictDeletedRemote ConflictKind = "deleted-remote"
+)
+
+// 无法写入冲突标记时，双方的完整内容保存在这两个旁路文件中
+const (
+	LocalSuffix  = ".local"
+	RemoteSuffix = ".remote"
+)
+
+// Conflict 一条未解决的合并冲突
+type Conflict struct {
+	Path string       `json:"path"`
+	Kind ConflictKind `json:"kind"`
+	// Markers 冲突已以 <<<<<<< 标记写入文件；为 false 时见 .local/.remote 旁路文件
+	Markers bool `json:"markers"`
+	// LocalInPlace 本地版本就是文件本身，没有 .local 旁路文件（watch --bidirectional 记录的冲突）
+	LocalInPlace bool `json:"localInPlace,omitempty"`
+	// Locations 冲突所在的行号或 JSON 键路径
+	Locations     []string `json:"locations,omitempty"`
+	LocalHash     string   `json:"localHash,omitempty"`
+	RemoteHash    string   `json:"remoteHash,omitempty"`
+	RemoteVersion string   `json:"remoteVersion,omitempty"`
+}
+
+// LocalFile 本地版本旁路文件的路径
+func (c *Conflict) LocalFile() string {
+	if c.LocalInPlace {
+		return c.Path
+	}
+	return c.Path + LocalSuffix
+}
+
+// RemoteFile 远端版本旁路文件的路径
+func (c *Conflict) RemoteFile() string {
+	return c.Path + RemoteSuffix
+}
+
+// LoadConflicts 读取未解决的冲突，没有记录时返回空
+func LoadConflicts(root string) ([]Conflict, error) {
+	path := filepath.Join(root, ConflictsFile)
+	if !utils.Exists(path) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取冲突记录失败: %w", err)
+	}
+
+	var conflicts []Conflict
+	if err := json.Unmarshal(data, &conflicts); err != nil {
+		return nil, fmt.Errorf("解析冲突记录失败 %s: %w", path, err)
+	}
+	return conflicts, nil
+}
+
+// SaveConflicts 写回未解决的冲突，全部解决后删除记录文件
+func SaveConflicts(root string, conflicts []Conflict) error {
+	path := filepath.Join(root, ConflictsFile)
+	if len(conflicts) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除冲突记录失败: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(conflicts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("保存冲突记录失败: %w", err)
+	}
+	return nil
+}
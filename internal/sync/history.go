@@ -0,0 +1,195 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geelato/cli/internal/platform"
+	"github.com/geelato/cli/pkg/crypto"
+	gerrors "github.com/geelato/cli/pkg/errors"
+)
+
+// VersionChange 某个平台版本相对上一版本的单个文件变化
+type VersionChange struct {
+	Path string
+	Type ChangeType
+	Old  []byte
+	New  []byte
+}
+
+// VersionDetail 平台版本的信息及其相对上一版本的变更
+type VersionDetail struct {
+	platform.SyncStatus
+	// Parent 上一版本，为空表示这是第一个版本
+	Parent  string
+	Changes []VersionChange
+}
+
+// Log 返回当前分支的版本历史，最新的在前
+func (s *SyncService) Log(ctx context.Context, limit int) ([]platform.SyncStatus, error) {
+	opts := s.pushOptions("")
+	versions, err := s.platform.ListVersions(ctx, opts.AppID, opts.Branch, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	return versions, nil
+}
+
+// Show 下载指定版本及其上一版本的应用包，返回两者之间的文件变化
+func (s *SyncService) Show(ctx context.Context, version string) (*VersionDetail, error) {
+	versions, err := s.Log(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var detail *VersionDetail
+	for i, v := range versions {
+		if v.Version != version {
+			continue
+		}
+		detail = &VersionDetail{SyncStatus: v}
+		if i+1 < len(versions) {
+			detail.Parent = versions[i+1].Version
+		}
+		break
+	}
+	if detail == nil {
+		return nil, gerrors.New(gerrors.ErrSyncVersion, fmt.Sprintf("平台上不存在版本 %s", version))
+	}
+
+	detail.Changes, err = s.versionChanges(ctx, detail.Parent, version)
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// RollbackPlan 回滚前计算出的变更：把最新版本 From 的内容恢复为版本 To
+type RollbackPlan struct {
+	AppID   string
+	From    string
+	To      string
+	Changes []VersionChange
+}
+
+// PlanRollback 比较最新版本与目标版本，得到回滚需要发布的变更，供确认前预览
+func (s *SyncService) PlanRollback(ctx context.Context, version string) (*RollbackPlan, error) {
+	versions, err := s.Log(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, gerrors.New(gerrors.ErrSyncVersion, "平台上还没有发布过版本")
+	}
+
+	found := false
+	for _, v := range versions {
+		if v.Version == version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, gerrors.New(gerrors.ErrSyncVersion, fmt.Sprintf("平台上不存在版本 %s", version))
+	}
+
+	latest := versions[0].Version
+	if latest == version {
+		return nil, gerrors.New(gerrors.ErrSyncVersion, fmt.Sprintf("%s 已经是最新版本，无需回滚", version))
+	}
+
+	changes, err := s.versionChanges(ctx, latest, version)
+	if err != nil {
+		return nil, err
+	}
+	return &RollbackPlan{AppID: s.pushOptions("").AppID, From: latest, To: version, Changes: changes}, nil
+}
+
+// Rollback 以最新版本为基线，把目标版本的内容重新发布为一个新版本，不修改本地文件。
+// 版本说明记录回滚的来源与目标，便于审计
+func (s *SyncService) Rollback(ctx context.Context, plan *RollbackPlan, reason string) (string, error) {
+	files := make([]platform.FileEntry, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		entry := platform.FileEntry{Path: change.Path, Action: string(change.Type)}
+		if change.Type != ChangeDeleted {
+			entry.Content = change.New
+			entry.Hash = crypto.SHA256String(change.New)
+		}
+		files = append(files, entry)
+	}
+
+	message := fmt.Sprintf("Rollback to %s (from %s)", plan.To, plan.From)
+	if reason != "" {
+		message += "\n\n" + reason
+	}
+
+	opts := s.pushOptions(message)
+	version, err := s.platform.UploadAppPackage(ctx, &platform.UploadRequest{
+		AppID:       plan.AppID,
+		BaseVersion: plan.From,
+		Branch:      opts.Branch,
+		Message:     message,
+		Author:      opts.Author,
+		Files:       files,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to publish rollback: %w", err)
+	}
+	return version, nil
+}
+
+// versionChanges 下载两个版本的应用包并比较，from 为空时视为空应用
+func (s *SyncService) versionChanges(ctx context.Context, from, to string) ([]VersionChange, error) {
+	target, err := s.fetchPackage(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	base := map[string][]byte{}
+	if from != "" {
+		if base, err = s.fetchPackage(ctx, from); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []VersionChange
+	for _, change := range Diff(packageHashes(base), packageHashes(target)) {
+		changes = append(changes, VersionChange{
+			Path: change.Path,
+			Type: change.Type,
+			Old:  base[change.Path],
+			New:  target[change.Path],
+		})
+	}
+	return changes, nil
+}
+
+// Checkout 把工作区恢复为指定的平台版本。存在未推送的本地修改或未解决的冲突时拒绝执行，
+// 因此本地文件与基线一致，拉取时每个文件都直接采用该版本的内容
+func (s *SyncService) Checkout(ctx context.Context, version string) (*PullResult, error) {
+	if err := s.checkUnresolved(); err != nil {
+		return nil, err
+	}
+
+	state, err := LoadState(s.cwd)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := DetectChanges(s.cwd, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect changes: %w", err)
+	}
+	if len(changes) > 0 {
+		return nil, gerrors.New(gerrors.ErrSync,
+			fmt.Sprintf("本地有 %d 个未推送的变更，请先 push 或撤销后再 checkout", len(changes)))
+	}
+
+	return s.PullVersion(ctx, version)
+}
+
+func packageHashes(files map[string][]byte) map[string]string {
+	hashes := make(map[string]string, len(files))
+	for p, data := range files {
+		hashes[p] = crypto.SHA256String(data)
+	}
+	return hashes
+}
@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// JSONChange 两个 JSON 文档之间的一处语义差异
+type JSONChange struct {
+	// Op 为 '+' 新增、'-' 删除、'~' 修改
+	Op   byte
+	Path string
+	// Old、New 为紧凑格式的 JSON 值
+	Old string
+	New string
+}
+
+func (c JSONChange) String() string {
+	switch c.Op {
+	case '+':
+		return fmt.Sprintf("+ %s: %s", c.Path, c.New)
+	case '-':
+		return fmt.Sprintf("- %s: %s", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, c.Old, c.New)
+	}
+}
+
+// DiffJSON 按键比较两个 JSON 文档，忽略格式与键顺序。
+// 带 id 等标识字段的对象数组按元素比较，路径形如 columns[id=c1].title
+func DiffJSON(a, b []byte) ([]JSONChange, error) {
+	av, err := parseJSON(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := parseJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []JSONChange
+	diffJSONValue("", jsonValue{v: av, ok: true}, jsonValue{v: bv, ok: true}, &changes)
+	return changes, nil
+}
+
+func diffJSONValue(path string, a, b jsonValue, changes *[]JSONChange) {
+	if jsonValueEqual(a, b) {
+		return
+	}
+
+	display := path
+	if display == "" {
+		display = "$"
+	}
+
+	switch {
+	case !a.ok:
+		*changes = append(*changes, JSONChange{Op: '+', Path: display, New: compactJSON(b.v)})
+		return
+	case !b.ok:
+		*changes = append(*changes, JSONChange{Op: '-', Path: display, Old: compactJSON(a.v)})
+		return
+	}
+
+	if ao, ok := a.v.(*jsonObject); ok {
+		if bo, ok := b.v.(*jsonObject); ok {
+			for _, key := range ao.keys {
+				diffJSONValue(joinJSONPath(path, key), field(ao, key), field(bo, key), changes)
+			}
+			for _, key := range bo.keys {
+				if _, exists := ao.values[key]; !exists {
+					diffJSONValue(joinJSONPath(path, key), jsonValue{}, field(bo, key), changes)
+				}
+			}
+			return
+		}
+	}
+
+	if aa, ok := a.v.([]interface{}); ok {
+		if ba, ok := b.v.([]interface{}); ok {
+			if key := identityKey(aa, ba); key != "" {
+				am, bm := indexArray(aa, key), indexArray(ba, key)
+				for _, id := range arrayIDs(aa, key) {
+					diffJSONValue(fmt.Sprintf("%s[%s=%s]", path, key, id), element(am, id), element(bm, id), changes)
+				}
+				for _, id := range arrayIDs(ba, key) {
+					if _, exists := am[id]; !exists {
+						diffJSONValue(fmt.Sprintf("%s[%s=%s]", path, key, id), jsonValue{}, element(bm, id), changes)
+					}
+				}
+				return
+			}
+		}
+	}
+
+	*changes = append(*changes, JSONChange{Op: '~', Path: display, Old: compactJSON(a.v), New: compactJSON(b.v)})
+}
+
+func compactJSON(v interface{}) string {
+	var buf bytes.Buffer
+	writeJSON(&buf, v)
+	return buf.String()
+}
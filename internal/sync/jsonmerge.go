@@ -0,0 +1,489 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonObject 保留键顺序的 JSON 对象，合并后写回时不打乱原文件的字段顺序
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]interface{})}
+}
+
+func (o *jsonObject) set(key string, value interface{}) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// jsonValue 合并中的一个值，ok 为 false 表示该侧不存在这个键或元素
+type jsonValue struct {
+	v  interface{}
+	ok bool
+}
+
+// arrayIdentityKeys 对象数组按这些字段识别同一元素，例如 *.columns.json 中列的 id
+var arrayIdentityKeys = []string{"id", "key", "name", "fieldName"}
+
+// mergeJSON 按键三方合并 JSON 文件。对象逐键合并，元素带 id 等标识的对象数组按元素合并，
+// 其余值两侧修改不同时记为冲突并保留本地值。任一侧不是合法 JSON 时返回 false，改用行级合并
+func mergeJSON(filePath string, base, local, remote []byte) (*MergeResult, bool) {
+	l, err := parseJSON(local)
+	if err != nil {
+		return nil, false
+	}
+	r, err := parseJSON(remote)
+	if err != nil {
+		return nil, false
+	}
+	b := jsonValue{}
+	if len(bytes.TrimSpace(base)) > 0 {
+		v, err := parseJSON(base)
+		if err != nil {
+			return nil, false
+		}
+		b = jsonValue{v: v, ok: true}
+	}
+
+	m := &jsonMerger{rules: metadataRules(filePath)}
+	merged := m.merge("", b, jsonValue{v: l, ok: true}, jsonValue{v: r, ok: true})
+
+	content, ok := formatJSON(merged.v, local)
+	if !ok {
+		return nil, false
+	}
+	return &MergeResult{Content: content, Conflicts: m.conflicts}, true
+}
+
+// formatJSON 按 like 的缩进与结尾换行输出 JSON，like 没有缩进行时保持紧凑格式
+func formatJSON(v interface{}, like []byte) ([]byte, bool) {
+	var buf bytes.Buffer
+	writeJSON(&buf, v)
+
+	var out bytes.Buffer
+	if indent, ok := detectIndent(like); ok {
+		if err := json.Indent(&out, buf.Bytes(), "", indent); err != nil {
+			return nil, false
+		}
+	} else {
+		out.Write(buf.Bytes())
+	}
+	if bytes.HasSuffix(like, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), true
+}
+
+// applyJSONPaths 把 side 中 paths 处的值写入 merged，其余内容保持 merged 不变，
+// paths 为 mergeJSON 记录的冲突键路径。任一内容不是合法 JSON 时返回 false
+func applyJSONPaths(merged, side []byte, paths []string) ([]byte, bool) {
+	m, err := parseJSON(merged)
+	if err != nil {
+		return nil, false
+	}
+	s, err := parseJSON(side)
+	if err != nil {
+		return nil, false
+	}
+
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p] = true
+	}
+	result := takeJSONPaths("", jsonValue{v: m, ok: true}, jsonValue{v: s, ok: true}, set)
+	if !result.ok {
+		return nil, false
+	}
+	return formatJSON(result.v, merged)
+}
+
+// takeJSONPaths 与 jsonMerger.merge 以相同方式遍历并生成键路径，路径在 paths 中时采用 s 的值
+func takeJSONPaths(path string, m, s jsonValue, paths map[string]bool) jsonValue {
+	if paths[path] || (path == "" && paths["$"]) {
+		return s
+	}
+	if !m.ok || !s.ok {
+		return m
+	}
+
+	mo, mIsObj := m.v.(*jsonObject)
+	so, sIsObj := s.v.(*jsonObject)
+	if mIsObj && sIsObj {
+		result := newJSONObject()
+		keys := append([]string{}, mo.keys...)
+		for _, key := range so.keys {
+			if _, exists := mo.values[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		for _, key := range keys {
+			if v := takeJSONPaths(joinJSONPath(path, key), field(mo, key), field(so, key), paths); v.ok {
+				result.set(key, v.v)
+			}
+		}
+		return jsonValue{v: result, ok: true}
+	}
+
+	ma, mIsArr := m.v.([]interface{})
+	sa, sIsArr := s.v.([]interface{})
+	if mIsArr && sIsArr {
+		key := identityKey(ma, sa)
+		if key == "" {
+			return m
+		}
+		mm, sm := indexArray(ma, key), indexArray(sa, key)
+		elementPath := func(id string) string { return fmt.Sprintf("%s[%s=%s]", path, key, id) }
+		ids := mergeIDs(arrayIDs(ma, key), arrayIDs(sa, key), func(id string) bool { return paths[elementPath(id)] })
+
+		result := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			if v := takeJSONPaths(elementPath(id), element(mm, id), element(sm, id), paths); v.ok {
+				result = append(result, v.v)
+			}
+		}
+		return jsonValue{v: result, ok: true}
+	}
+	return m
+}
+
+type jsonMerger struct {
+	// rules 文件类型对应的元数据字段规则，键为 JSON 路径
+	rules     map[string]scalarRule
+	conflicts []string
+}
+
+func (m *jsonMerger) merge(path string, b, l, r jsonValue) jsonValue {
+	switch {
+	case jsonValueEqual(l, r), jsonValueEqual(b, r):
+		return l
+	case jsonValueEqual(b, l):
+		return r
+	}
+
+	if l.ok && r.ok {
+		lo, lIsObj := l.v.(*jsonObject)
+		ro, rIsObj := r.v.(*jsonObject)
+		if lIsObj && rIsObj {
+			bo, _ := b.v.(*jsonObject)
+			if bo == nil {
+				bo = newJSONObject()
+			}
+			return jsonValue{v: m.mergeObject(path, bo, lo, ro), ok: true}
+		}
+
+		la, lIsArr := l.v.([]interface{})
+		ra, rIsArr := r.v.([]interface{})
+		if lIsArr && rIsArr {
+			ba, _ := b.v.([]interface{})
+			if key := identityKey(ba, la, ra); key != "" {
+				return jsonValue{v: m.mergeArray(path, key, ba, la, ra), ok: true}
+			}
+		}
+	}
+
+	if v, ok := resolveScalar(m.rules[path], l, r); ok {
+		return v
+	}
+
+	if path == "" {
+		path = "$"
+	}
+	m.conflicts = append(m.conflicts, path)
+	return l
+}
+
+func (m *jsonMerger) mergeObject(path string, b, l, r *jsonObject) *jsonObject {
+	result := newJSONObject()
+
+	keys := append([]string{}, l.keys...)
+	for _, key := range r.keys {
+		if _, exists := l.values[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		merged := m.merge(joinJSONPath(path, key), field(b, key), field(l, key), field(r, key))
+		if merged.ok {
+			result.set(key, merged.v)
+		}
+	}
+	return result
+}
+
+// mergeArray 按标识字段合并对象数组：以本地顺序为准，远端新增的元素插入到它在远端的前一个元素之后
+func (m *jsonMerger) mergeArray(path, key string, b, l, r []interface{}) []interface{} {
+	bm, lm, rm := indexArray(b, key), indexArray(l, key), indexArray(r, key)
+
+	ids := mergeIDs(arrayIDs(l, key), arrayIDs(r, key), func(string) bool { return true })
+
+	result := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		merged := m.merge(fmt.Sprintf("%s[%s=%s]", path, key, id), element(bm, id), element(lm, id), element(rm, id))
+		if merged.ok {
+			result = append(result, merged.v)
+		}
+	}
+	return result
+}
+
+// mergeIDs 以 ids 的顺序为准，把 remoteIDs 中不在 ids 里且 include 为 true 的元素插入到它在远端的前一个元素之后
+func mergeIDs(ids, remoteIDs []string, include func(id string) bool) []string {
+	position := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		position[id] = true
+	}
+
+	for i, id := range remoteIDs {
+		if position[id] || !include(id) {
+			continue
+		}
+		insertAt := 0
+		for p := i - 1; p >= 0; p-- {
+			if idx := indexOf(ids, remoteIDs[p]); idx >= 0 {
+				insertAt = idx + 1
+				break
+			}
+		}
+		ids = append(ids[:insertAt], append([]string{id}, ids[insertAt:]...)...)
+		position[id] = true
+	}
+	return ids
+}
+
+// identityKey 选择三侧数组共同可用的标识字段：所有元素都是对象且该字段为互不重复的字符串
+func identityKey(arrays ...[]interface{}) string {
+	for _, key := range arrayIdentityKeys {
+		usable := true
+		for _, arr := range arrays {
+			seen := make(map[string]bool, len(arr))
+			for _, item := range arr {
+				obj, ok := item.(*jsonObject)
+				if !ok {
+					usable = false
+					break
+				}
+				id, ok := obj.values[key].(string)
+				if !ok || id == "" || seen[id] {
+					usable = false
+					break
+				}
+				seen[id] = true
+			}
+			if !usable {
+				break
+			}
+		}
+		if usable {
+			return key
+		}
+	}
+	return ""
+}
+
+func indexArray(arr []interface{}, key string) map[string]interface{} {
+	index := make(map[string]interface{}, len(arr))
+	for _, item := range arr {
+		obj := item.(*jsonObject)
+		index[obj.values[key].(string)] = item
+	}
+	return index
+}
+
+func arrayIDs(arr []interface{}, key string) []string {
+	ids := make([]string, 0, len(arr))
+	for _, item := range arr {
+		ids = append(ids, item.(*jsonObject).values[key].(string))
+	}
+	return ids
+}
+
+func indexOf(items []string, item string) int {
+	for i, s := range items {
+		if s == item {
+			return i
+		}
+	}
+	return -1
+}
+
+func field(o *jsonObject, key string) jsonValue {
+	v, ok := o.values[key]
+	return jsonValue{v: v, ok: ok}
+}
+
+func element(index map[string]interface{}, id string) jsonValue {
+	v, ok := index[id]
+	return jsonValue{v: v, ok: ok}
+}
+
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func jsonValueEqual(a, b jsonValue) bool {
+	if !a.ok || !b.ok {
+		return a.ok == b.ok
+	}
+	return jsonEqual(a.v, b.v)
+}
+
+// jsonEqual 比较两个 JSON 值，对象比较时忽略键顺序
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case *jsonObject:
+		bv, ok := b.(*jsonObject)
+		if !ok || len(av.values) != len(bv.values) {
+			return false
+		}
+		for key, value := range av.values {
+			other, exists := bv.values[key]
+			if !exists || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// parseJSON 解析 JSON，对象保留键顺序，数字保留原始写法
+func parseJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("JSON 之后存在多余内容")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := newJSONObject()
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj.set(keyTok.(string), value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			arr := []interface{}{}
+			for dec.More() {
+				value, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		}
+		return nil, fmt.Errorf("意外的 JSON 分隔符 %v", t)
+	default:
+		return t, nil
+	}
+}
+
+// writeJSON 输出紧凑的 JSON，字符串中的 <、>、& 不做转义，保持页面源码等内容原样
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	switch t := v.(type) {
+	case *jsonObject:
+		buf.WriteByte('{')
+		for i, key := range t.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			writeJSON(buf, t.values[key])
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, item)
+		}
+		buf.WriteByte(']')
+	case string:
+		writeJSONString(buf, t)
+	case json.Number:
+		buf.WriteString(t.String())
+	case bool:
+		if t {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	default:
+		buf.WriteString("null")
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	buf.Truncate(buf.Len() - 1)
+}
+
+// detectIndent 沿用原文件第一处缩进的写法，没有缩进行时返回 false
+func detectIndent(data []byte) (string, bool) {
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)], true
+		}
+	}
+	return "", false
+}
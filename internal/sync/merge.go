@@ -0,0 +1,200 @@
+package sync
+
+import (
+	"bytes"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// MergeResult 三方合并的结果
+type MergeResult struct {
+	// Content 合并后的内容。文本冲突时包含冲突标记；JSON 冲突时冲突处保留本地的值
+	Content []byte
+	// Conflicts 冲突位置：文本文件为冲突块所在行号，JSON 文件为冲突的键路径
+	Conflicts []string
+	// Markers 冲突是否以标记的形式写在 Content 中，否则需要 .local/.remote 旁路文件
+	Markers bool
+}
+
+// Clean 合并是否没有冲突
+func (r *MergeResult) Clean() bool {
+	return len(r.Conflicts) == 0
+}
+
+// mergeLineLimit 文本行级合并的规模上限（两侧行数之积），超过时整个文件按一处冲突处理
+const mergeLineLimit = 4000000
+
+// Merge3 以 base 为共同祖先合并本地与远端的修改。
+// .json 文件按键合并，*.api.js 的文件头按标签合并，二进制文件无法合并，其余按行合并。
+// base 为 nil 表示没有共同祖先
+func Merge3(filePath string, base, local, remote []byte, remoteLabel string) *MergeResult {
+	if IsBinary(local) || IsBinary(remote) {
+		return &MergeResult{Content: local, Conflicts: []string{"binary"}}
+	}
+
+	if strings.EqualFold(path.Ext(filePath), ".json") {
+		if result, ok := mergeJSON(filePath, base, local, remote); ok {
+			return result
+		}
+	}
+
+	if strings.HasSuffix(filePath, ".api.js") {
+		if result, ok := mergeAPIScript(base, local, remote, remoteLabel); ok {
+			return result
+		}
+	}
+
+	return mergeText(base, local, remote, remoteLabel)
+}
+
+// IsBinary 按前 8000 字节中是否含 NUL 判断二进制内容，与 git 的判断方式一致
+func IsBinary(data []byte) bool {
+	n := len(data)
+	if n > 8000 {
+		n = 8000
+	}
+	return bytes.IndexByte(data[:n], 0) >= 0
+}
+
+// mergeText 行级 diff3：以 base 与两侧的公共行为锚点切分，只有一侧修改的块直接采用，
+// 两侧修改不同的块写入 <<<<<<< / ======= / >>>>>>> 冲突标记
+func mergeText(base, local, remote []byte, remoteLabel string) *MergeResult {
+	b, l, r := splitLines(base), splitLines(local), splitLines(remote)
+	result := &MergeResult{Markers: true}
+
+	if len(b)*len(l) > mergeLineLimit || len(b)*len(r) > mergeLineLimit {
+		var buf bytes.Buffer
+		writeConflict(&buf, l, r, remoteLabel)
+		result.Content = buf.Bytes()
+		result.Conflicts = []string{"1"}
+		return result
+	}
+
+	ml := matchLines(b, l)
+	mr := matchLines(b, r)
+
+	var buf bytes.Buffer
+	line := 1
+	i, j, k := 0, 0, 0
+	emit := func(bEnd, lEnd, rEnd int) {
+		bc, lc, rc := b[i:bEnd], l[j:lEnd], r[k:rEnd]
+		var out []string
+		switch {
+		case equalLines(lc, rc), equalLines(bc, rc):
+			out = lc
+		case equalLines(bc, lc):
+			out = rc
+		default:
+			result.Conflicts = append(result.Conflicts, strconv.Itoa(line))
+			line += writeConflict(&buf, lc, rc, remoteLabel)
+			return
+		}
+		for _, s := range out {
+			buf.WriteString(s)
+		}
+		line += len(out)
+	}
+
+	for bi := 0; bi < len(b); bi++ {
+		if ml[bi] < 0 || mr[bi] < 0 {
+			continue
+		}
+		emit(bi, ml[bi], mr[bi])
+		buf.WriteString(l[ml[bi]])
+		line++
+		i, j, k = bi+1, ml[bi]+1, mr[bi]+1
+	}
+	emit(len(b), len(l), len(r))
+
+	result.Content = buf.Bytes()
+	return result
+}
+
+// writeConflict 写入一个冲突块，返回写入的行数
+func writeConflict(buf *bytes.Buffer, local, remote []string, remoteLabel string) int {
+	label := "remote"
+	if remoteLabel != "" {
+		label += " (" + remoteLabel + ")"
+	}
+
+	buf.WriteString("<<<<<<< local\n")
+	writeBlock(buf, local)
+	buf.WriteString("=======\n")
+	writeBlock(buf, remote)
+	buf.WriteString(">>>>>>> " + label + "\n")
+	return len(local) + len(remote) + 3
+}
+
+func writeBlock(buf *bytes.Buffer, lines []string) {
+	for _, s := range lines {
+		buf.WriteString(s)
+	}
+	if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		buf.WriteByte('\n')
+	}
+}
+
+// splitLines 按行切分并保留换行符，拼接后与原内容一致
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchLines 计算 a 与 b 的最长公共子序列，返回 a 中每行在 b 中匹配的下标，未匹配为 -1
+func matchLines(a, b []string) []int {
+	n, m := len(a), len(b)
+	lcs := make([][]int32, n+1)
+	for x := range lcs {
+		lcs[x] = make([]int32, m+1)
+	}
+	for x := n - 1; x >= 0; x-- {
+		for y := m - 1; y >= 0; y-- {
+			switch {
+			case a[x] == b[y]:
+				lcs[x][y] = lcs[x+1][y+1] + 1
+			case lcs[x+1][y] >= lcs[x][y+1]:
+				lcs[x][y] = lcs[x+1][y]
+			default:
+				lcs[x][y] = lcs[x][y+1]
+			}
+		}
+	}
+
+	match := make([]int, n)
+	for x := range match {
+		match[x] = -1
+	}
+	x, y := 0, 0
+	for x < n && y < m {
+		switch {
+		case a[x] == b[y]:
+			match[x] = y
+			x++
+			y++
+		case lcs[x+1][y] >= lcs[x][y+1]:
+			x++
+		default:
+			y++
+		}
+	}
+	return match
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
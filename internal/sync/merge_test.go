@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	const apiBase = "/**\n * @name list\n * @path /list\n * @version 1.0.0\n */\nconst a = 1;\nconst b = 2;\nconst c = 3;\n"
+
+	tests := []struct {
+		name      string
+		path      string
+		base      string
+		local     string
+		remote    string
+		want      string
+		conflicts []string
+		markers   bool
+	}{
+		// 行级合并
+		{"text clean edits", "page/a.vue",
+			"a\nb\nc\n", "A\nb\nc\n", "a\nb\nC\n",
+			"A\nb\nC\n", nil, true},
+		{"text same edit", "page/a.vue",
+			"a\nb\nc\n", "a\nB\nc\n", "a\nB\nc\n",
+			"a\nB\nc\n", nil, true},
+		{"text overlapping edits", "page/a.vue",
+			"a\nb\nc\n", "a\nL\nc\n", "a\nR\nc\n",
+			"a\n<<<<<<< local\nL\n=======\nR\n>>>>>>> remote (v2)\nc\n", []string{"2"}, true},
+		{"text clean edits without trailing newline", "page/a.vue",
+			"a\nb\nc", "A\nb\nc", "a\nb\nC",
+			"A\nb\nC", nil, true},
+		{"text conflict without trailing newline", "page/a.vue",
+			"a\nb", "a\nL", "a\nR",
+			"a\n<<<<<<< local\nL\n=======\nR\n>>>>>>> remote (v2)\n", []string{"2"}, true},
+
+		// JSON 按键合并
+		{"json different keys", "meta/User/User.define.json",
+			`{"a":1,"b":1}`, `{"a":2,"b":1}`, `{"a":1,"b":2,"c":3}`,
+			`{"a":2,"b":2,"c":3}`, nil, false},
+		{"json same key", "meta/User/User.define.json",
+			`{"a":1}`, `{"a":2}`, `{"a":3}`,
+			`{"a":2}`, []string{"a"}, false},
+		{"json key deleted on one side", "meta/User/User.define.json",
+			`{"a":1,"b":1}`, `{"a":1}`, `{"a":2,"b":1}`,
+			`{"a":2}`, nil, false},
+		{"json keeps local indent", "meta/User/User.define.json",
+			"{\n    \"a\": 1,\n    \"b\": 1\n}\n", "{\n    \"a\": 2,\n    \"b\": 1\n}\n", `{"a":1,"b":2}`,
+			"{\n    \"a\": 2,\n    \"b\": 2\n}\n", nil, false},
+		{"json array insert by id", "meta/User/User.columns.json",
+			`{"cols":[{"id":"c1","len":1},{"id":"c2","len":1}]}`,
+			`{"cols":[{"id":"c1","len":1},{"id":"c2","len":5}]}`,
+			`{"cols":[{"id":"c1","len":1},{"id":"c3","len":1},{"id":"c2","len":1}]}`,
+			`{"cols":[{"id":"c1","len":1},{"id":"c3","len":1},{"id":"c2","len":5}]}`, nil, false},
+		{"json array delete by id", "meta/User/User.columns.json",
+			`{"cols":[{"id":"c1","len":1},{"id":"c2","len":1}]}`,
+			`{"cols":[{"id":"c1","len":1}]}`,
+			`{"cols":[{"id":"c1","len":9},{"id":"c2","len":1}]}`,
+			`{"cols":[{"id":"c1","len":9}]}`, nil, false},
+		{"json array delete of modified element", "meta/User/User.columns.json",
+			`{"cols":[{"id":"c1","len":1},{"id":"c2","len":1}]}`,
+			`{"cols":[{"id":"c1","len":1},{"id":"c2","len":5}]}`,
+			`{"cols":[{"id":"c1","len":1}]}`,
+			`{"cols":[{"id":"c1","len":1},{"id":"c2","len":5}]}`, []string{"cols[id=c2]"}, false},
+		{"invalid json falls back to lines", "meta/User/User.define.json",
+			"a\nb\nc\n", "A\nb\nc\n", "a\nb\nC\n",
+			"A\nb\nC\n", nil, true},
+
+		// 元数据字段规则
+		{"meta version takes higher", "meta/User/User.define.json",
+			`{"meta":{"version":"1.0.0"}}`, `{"meta":{"version":"1.0.10"}}`, `{"meta":{"version":"1.0.9"}}`,
+			`{"meta":{"version":"1.0.10"}}`, nil, false},
+		{"page version takes higher", "page/home/home.define.json",
+			`{"page":{"version":3}}`, `{"page":{"version":4}}`, `{"page":{"version":5}}`,
+			`{"page":{"version":5}}`, nil, false},
+		{"nested version is not metadata", "meta/User/User.columns.json",
+			`{"cols":[{"id":"c1","version":"1"}]}`, `{"cols":[{"id":"c1","version":"2"}]}`, `{"cols":[{"id":"c1","version":"3"}]}`,
+			`{"cols":[{"id":"c1","version":"2"}]}`, []string{"cols[id=c1].version"}, false},
+		{"version outside app dirs conflicts", "geelato.json",
+			`{"meta":{"version":"1"}}`, `{"meta":{"version":"2"}}`, `{"meta":{"version":"3"}}`,
+			`{"meta":{"version":"2"}}`, []string{"meta.version"}, false},
+		{"updatedAt takes newer time", "workflow/leave.json",
+			`{"meta":{"updatedAt":"2024-01-01T00:00:00Z"}}`,
+			`{"meta":{"updatedAt":"2024-01-02T00:00:00Z"}}`,
+			`{"meta":{"updatedAt":"2024-01-02T08:00:00+09:00"}}`,
+			`{"meta":{"updatedAt":"2024-01-02T00:00:00Z"}}`, nil, false},
+		{"unparsable updatedAt conflicts", "workflow/leave.json",
+			`{"meta":{"updatedAt":"yesterday"}}`, `{"meta":{"updatedAt":"today"}}`, `{"meta":{"updatedAt":"now"}}`,
+			`{"meta":{"updatedAt":"today"}}`, []string{"meta.updatedAt"}, false},
+
+		// API 脚本
+		{"api script tags and body", "api/list.api.js",
+			apiBase,
+			"/**\n * @name list\n * @path /list\n * @version 1.0.1\n */\nconst a = 10;\nconst b = 2;\nconst c = 3;\n",
+			"/**\n * @name list\n * @path /list\n * @version 1.0.2\n * @auth admin\n */\nconst a = 1;\nconst b = 2;\nconst c = 30;\n",
+			"/**\n * @name list\n * @path /list\n * @version 1.0.2\n * @auth admin\n */\nconst a = 10;\nconst b = 2;\nconst c = 30;\n", nil, true},
+		{"api script conflicting tag", "api/list.api.js",
+			apiBase,
+			"/**\n * @name list\n * @path /local\n * @version 1.0.0\n */\nconst a = 1;\nconst b = 2;\nconst c = 3;\n",
+			"/**\n * @name list\n * @path /remote\n * @version 1.0.0\n */\nconst a = 1;\nconst b = 2;\nconst c = 3;\n",
+			"/**\n * @name list\n<<<<<<< local\n * @path /local\n=======\n * @path /remote\n>>>>>>> remote (v2)\n * @version 1.0.0\n */\nconst a = 1;\nconst b = 2;\nconst c = 3;\n",
+			[]string{"3"}, true},
+		{"api script body conflict line", "api/list.api.js",
+			apiBase,
+			"/**\n * @name list\n * @path /list\n * @version 1.0.0\n */\nconst a = 1;\nconst b = 20;\nconst c = 3;\n",
+			"/**\n * @name list\n * @path /list\n * @version 1.0.0\n */\nconst a = 1;\nconst b = 21;\nconst c = 3;\n",
+			"/**\n * @name list\n * @path /list\n * @version 1.0.0\n */\nconst a = 1;\n<<<<<<< local\nconst b = 20;\n=======\nconst b = 21;\n>>>>>>> remote (v2)\nconst c = 3;\n",
+			[]string{"7"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Merge3(tt.path, []byte(tt.base), []byte(tt.local), []byte(tt.remote), "v2")
+			if got := string(result.Content); got != tt.want {
+				t.Errorf("content:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if !reflect.DeepEqual(result.Conflicts, tt.conflicts) {
+				t.Errorf("conflicts = %q, want %q", result.Conflicts, tt.conflicts)
+			}
+			if result.Markers != tt.markers {
+				t.Errorf("markers = %v, want %v", result.Markers, tt.markers)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package sync
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/geelato/cli/internal/file"
+	"github.com/geelato/cli/pkg/crypto"
+	"github.com/geelato/cli/pkg/utils"
+)
+
+// PullResult 拉取结果
+type PullResult struct {
+	Version string
+	// Updated 本地未修改、直接采用远端内容的文件
+	Updated []string
+	// Merged 两侧都有修改且自动合并成功的文件
+	Merged []string
+	// Deleted 远端已删除、本地未修改而随之删除的文件
+	Deleted []string
+	// Kept 只有本地修改、保留本地内容的文件
+	Kept      []string
+	Conflicts []Conflict
+}
+
+// ReadPackage 读取平台下载的应用包，返回相对路径到文件内容的映射。
+// 被 ignore 忽略的文件不会出现在结果中，拉取时既不写入也不删除本地的同名文件
+func ReadPackage(data []byte, ignore *file.Ignore) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("解析应用包失败: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(filepath.ToSlash(entry.Name), "./"))
+		if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("应用包中包含非法路径: %s", entry.Name)
+		}
+		if ignore.Ignored(name, false) {
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+
+	return files, nil
+}
+
+// PullChanges 以上次同步的内容为共同祖先，把远端应用包三方合并到本地：
+// 只有一侧修改的文件直接采用该侧内容，两侧都修改的文件按 JSON 键或文本行合并，
+// 无法自动合并的冲突写入冲突标记或 .local/.remote 旁路文件并记录到 ConflictsFile。
+// 合并完成后同步状态与基线更新为远端版本，本地尚未推送的修改仍会被 push 识别
+func PullChanges(root string, state *State, remote map[string][]byte, version string) (*PullResult, error) {
+	return pullChanges(root, state, remote, version, false)
+}
+
+// ApplyRemoteChanges 与 PullChanges 相同，但从不改写本地修改过的文件：两侧都修改的文件不做合并，
+// 直接记为冲突，本地文件保持原样，远端内容写入 .remote 旁路文件。新冲突追加到已有的冲突记录中
+func ApplyRemoteChanges(root string, state *State, remote map[string][]byte, version string) (*PullResult, error) {
+	return pullChanges(root, state, remote, version, true)
+}
+
+func pullChanges(root string, state *State, remote map[string][]byte, version string, noMerge bool) (*PullResult, error) {
+	local, err := ScanFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(remote)+len(state.Files))
+	for p := range remote {
+		paths = append(paths, p)
+	}
+	for p := range state.Files {
+		if _, exists := remote[p]; !exists {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	result := &PullResult{Version: version}
+	for _, p := range paths {
+		remoteData, inRemote := remote[p]
+		remoteHash := ""
+		if inRemote {
+			remoteHash = crypto.SHA256String(remoteData)
+		}
+		localHash, inLocal := local[p]
+		baseHash, inBase := state.Files[p]
+
+		if err := mergeFile(root, p, result, fileVersions{
+			remoteData: remoteData,
+			inRemote:   inRemote,
+			remoteHash: remoteHash,
+			inLocal:    inLocal,
+			localHash:  localHash,
+			inBase:     inBase,
+			baseHash:   baseHash,
+			readBase:   func() []byte { return ReadBase(root, p) },
+			noMerge:    noMerge,
+		}); err != nil {
+			return nil, err
+		}
+
+		if inRemote {
+			if err := WriteBase(root, p, remoteData); err != nil {
+				return nil, err
+			}
+			state.Files[p] = remoteHash
+		} else {
+			if err := RemoveBase(root, p); err != nil {
+				return nil, err
+			}
+			delete(state.Files, p)
+		}
+	}
+
+	state.Apply(nil, version)
+	if err := state.Save(root); err != nil {
+		return nil, err
+	}
+
+	conflicts := result.Conflicts
+	if noMerge {
+		if conflicts, err = appendConflicts(root, result.Conflicts); err != nil {
+			return nil, err
+		}
+	}
+	if err := SaveConflicts(root, conflicts); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// appendConflicts 把新冲突合入已有的冲突记录，同一文件以新冲突为准
+func appendConflicts(root string, added []Conflict) ([]Conflict, error) {
+	existing, err := LoadConflicts(root)
+	if err != nil {
+		return nil, err
+	}
+	replaced := make(map[string]bool, len(added))
+	for _, c := range added {
+		replaced[c.Path] = true
+	}
+
+	var conflicts []Conflict
+	for _, c := range existing {
+		if !replaced[c.Path] {
+			conflicts = append(conflicts, c)
+		}
+	}
+	return append(conflicts, added...), nil
+}
+
+// fileVersions 单个文件在本地、远端与上次同步时的状态
+type fileVersions struct {
+	remoteData []byte
+	inRemote   bool
+	remoteHash string
+	inLocal    bool
+	localHash  string
+	inBase     bool
+	baseHash   string
+	// readBase 读取共同祖先的内容，只在两侧都修改时才需要
+	readBase func() []byte
+	// noMerge 两侧都修改时不合并，只记录冲突
+	noMerge bool
+}
+
+func mergeFile(root, p string, result *PullResult, v fileVersions) error {
+	fullPath := filepath.Join(root, filepath.FromSlash(p))
+
+	if v.inLocal == v.inRemote && v.localHash == v.remoteHash {
+		return nil
+	}
+
+	localChanged := v.inLocal != v.inBase || v.localHash != v.baseHash
+	remoteChanged := v.inRemote != v.inBase || v.remoteHash != v.baseHash
+
+	switch {
+	case !remoteChanged:
+		result.Kept = append(result.Kept, p)
+		return nil
+	case !localChanged:
+		if !v.inRemote {
+			result.Deleted = append(result.Deleted, p)
+			return os.Remove(fullPath)
+		}
+		result.Updated = append(result.Updated, p)
+		return utils.WriteFile(fullPath, v.remoteData, 0644)
+	}
+
+	conflict := Conflict{
+		Path:          p,
+		Kind:          ConflictContent,
+		LocalHash:     v.localHash,
+		RemoteHash:    v.remoteHash,
+		RemoteVersion: result.Version,
+	}
+
+	switch {
+	case !v.inRemote:
+		// 远端删除、本地修改：保留本地文件，由用户决定是否删除
+		conflict.Kind = ConflictDeletedRemote
+	case !v.inLocal:
+		// 本地删除、远端修改：远端内容放到旁路文件
+		conflict.Kind = ConflictDeletedLocal
+		if err := utils.WriteFile(fullPath+RemoteSuffix, v.remoteData, 0644); err != nil {
+			return err
+		}
+	case v.noMerge:
+		// 本地文件保持原样，远端内容放到旁路文件
+		conflict.LocalInPlace = true
+		if err := utils.WriteFile(fullPath+RemoteSuffix, v.remoteData, 0644); err != nil {
+			return err
+		}
+	default:
+		localData, err := os.ReadFile(fullPath)
+		if err != nil {
+			return err
+		}
+
+		merged := Merge3(p, v.readBase(), localData, v.remoteData, result.Version)
+		if err := utils.WriteFile(fullPath, merged.Content, 0644); err != nil {
+			return err
+		}
+		if merged.Clean() {
+			result.Merged = append(result.Merged, p)
+			return nil
+		}
+
+		conflict.Markers = merged.Markers
+		conflict.Locations = merged.Conflicts
+		if !merged.Markers {
+			if err := utils.WriteFile(fullPath+LocalSuffix, localData, 0644); err != nil {
+				return err
+			}
+			if err := utils.WriteFile(fullPath+RemoteSuffix, v.remoteData, 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	result.Conflicts = append(result.Conflicts, conflict)
+	return nil
+}
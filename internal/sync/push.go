@@ -0,0 +1,172 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/geelato/cli/internal/platform"
+)
+
+// PushOptions 推送时附带的版本信息
+type PushOptions struct {
+	AppID   string
+	Branch  string
+	Message string
+	Author  string
+	// Force 跳过冲突检测，用本地变更覆盖平台上的修改
+	Force bool
+}
+
+// PushResult 推送结果
+type PushResult struct {
+	Version   string
+	Changes   []Change
+	Conflicts []platform.ConflictInfo
+}
+
+// CheckConflicts 以同步状态中的版本为基线，询问平台本次变更是否与目标分支上他人的修改冲突。
+// 尚未同步过（没有基线版本）时无从比较，直接返回空
+func CheckConflicts(ctx context.Context, client *platform.Client, appID, branch string, state *State, changes []Change) ([]platform.ConflictInfo, error) {
+	if state.Version == "" || len(changes) == 0 {
+		return nil, nil
+	}
+
+	files := make([]platform.FileEntry, 0, len(changes))
+	for _, change := range changes {
+		files = append(files, platform.FileEntry{
+			Path:   change.Path,
+			Hash:   change.LocalHash,
+			Action: string(change.Type),
+		})
+	}
+
+	result, err := client.CheckConflict(ctx, appID, branch, state.Version, files)
+	if err != nil {
+		return nil, err
+	}
+	if !result.HasConflict {
+		return nil, nil
+	}
+	return result.Conflicts, nil
+}
+
+// PushChanges 通过 UploadAppPackage 只上传变更的文件，删除的文件只携带路径。
+// 上传成功后才原子地更新同步状态，失败时状态保持不变，下次推送会重新计算同一变更集
+func PushChanges(ctx context.Context, client *platform.Client, root string, state *State, changes []Change, opts PushOptions) (string, error) {
+	files := make([]platform.FileEntry, 0, len(changes))
+	for _, change := range changes {
+		entry := platform.FileEntry{
+			Path:   change.Path,
+			Hash:   change.LocalHash,
+			Action: string(change.Type),
+		}
+
+		if change.Type != ChangeDeleted {
+			content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(change.Path)))
+			if err != nil {
+				return "", fmt.Errorf("读取文件失败: %w", err)
+			}
+			entry.Content = content
+		}
+
+		files = append(files, entry)
+	}
+
+	version, err := client.UploadAppPackage(ctx, &platform.UploadRequest{
+		AppID:       opts.AppID,
+		BaseVersion: state.Version,
+		Branch:      opts.Branch,
+		Message:     opts.Message,
+		Author:      opts.Author,
+		Files:       files,
+		Force:       opts.Force,
+		MergeFrom:   state.MergeFrom,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range files {
+		if entry.Action == platform.FileActionDelete {
+			err = RemoveBase(root, entry.Path)
+		} else {
+			err = WriteBase(root, entry.Path, entry.Content)
+		}
+		if err != nil {
+			return version, err
+		}
+	}
+
+	state.Apply(changes, version)
+	state.Branch = opts.Branch
+	state.MergeFrom = ""
+	if err := state.Save(root); err != nil {
+		return version, err
+	}
+
+	return version, nil
+}
+
+// PackageFile 推送时发送的单个文件，删除的文件只携带路径
+type PackageFile struct {
+	Path   string     `json:"path"`
+	Action ChangeType `json:"action"`
+	Type   string     `json:"type"`
+	Size   int64      `json:"size"`
+	Hash   string     `json:"hash,omitempty"`
+}
+
+// PushPlan 推送将要发送的内容
+type PushPlan struct {
+	Branch      string        `json:"branch"`
+	BaseVersion string        `json:"baseVersion"`
+	Files       []PackageFile `json:"files"`
+	// Size 上传的文件内容总字节数
+	Size int64 `json:"size"`
+	// Unchanged 自上次同步以来未修改、不会上传的文件数
+	Unchanged int           `json:"unchanged"`
+	Skipped   []SkippedFile `json:"skipped"`
+}
+
+// PlanPush 按 Push 相同的规则计算变更集，返回将要发送的文件与跳过的文件，不与平台通信
+func (s *SyncService) PlanPush() (*PushPlan, error) {
+	state, err := LoadState(s.cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	files, skipped, err := scanFiles(s.cwd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect changes: %w", err)
+	}
+	base, err := unignored(s.cwd, state.Files)
+	if err != nil {
+		return nil, err
+	}
+	changes := Diff(base, files)
+
+	plan := &PushPlan{
+		Branch:      s.Branch(),
+		BaseVersion: state.Version,
+		Files:       make([]PackageFile, 0, len(changes)),
+		Unchanged:   len(files),
+		Skipped:     skipped,
+	}
+	for _, change := range changes {
+		f := PackageFile{Path: change.Path, Action: change.Type, Type: FileType(change.Path)}
+		if change.Type != ChangeDeleted {
+			info, err := os.Stat(filepath.Join(s.cwd, filepath.FromSlash(change.Path)))
+			if err != nil {
+				return nil, err
+			}
+			f.Size = info.Size()
+			f.Hash = change.LocalHash
+			plan.Size += f.Size
+			plan.Unchanged--
+		}
+		plan.Files = append(plan.Files, f)
+	}
+	return plan, nil
+}
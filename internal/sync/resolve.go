@@ -0,0 +1,186 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/geelato/cli/pkg/utils"
+)
+
+// Resolution 冲突的解决方式
+type Resolution string
+
+const (
+	// ResolveLocal 冲突块采用本地内容；JSON 冲突的键路径采用 .local 中的值，其他旁路文件形式的冲突整个文件采用 .local
+	ResolveLocal Resolution = "local"
+	// ResolveRemote 冲突块采用远端内容；JSON 冲突的键路径采用 .remote 中的值，其他旁路文件形式的冲突整个文件采用 .remote
+	ResolveRemote Resolution = "remote"
+	// ResolveEdited 文件已手动编辑完成，只做校验
+	ResolveEdited Resolution = "edited"
+)
+
+const (
+	markerLocal  = "<<<<<<< "
+	markerSep    = "======="
+	markerRemote = ">>>>>>> "
+)
+
+// Sides 返回冲突双方的内容，用于展示差异。文件不存在的一侧返回 nil
+func (c *Conflict) Sides(root string) (local, remote []byte, err error) {
+	fullPath := filepath.Join(root, filepath.FromSlash(c.Path))
+
+	switch c.Kind {
+	case ConflictDeletedLocal:
+		remote, err = os.ReadFile(fullPath + RemoteSuffix)
+		return nil, remote, err
+	case ConflictDeletedRemote:
+		local, err = os.ReadFile(fullPath)
+		return local, nil, err
+	}
+
+	if c.Markers {
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		local, remote = SplitConflictMarkers(data)
+		return local, remote, nil
+	}
+
+	localFile := fullPath + LocalSuffix
+	if c.LocalInPlace {
+		localFile = fullPath
+	}
+	if local, err = os.ReadFile(localFile); err != nil {
+		return nil, nil, err
+	}
+	if remote, err = os.ReadFile(fullPath + RemoteSuffix); err != nil {
+		return nil, nil, err
+	}
+	return local, remote, nil
+}
+
+// SplitConflictMarkers 把带冲突标记的内容拆成本地与远端两个版本，冲突块之外的内容两侧相同
+func SplitConflictMarkers(data []byte) (local, remote []byte) {
+	var l, r bytes.Buffer
+	section := 0 // 0 冲突块外，1 本地块，2 远端块
+
+	for _, line := range splitLines(data) {
+		trimmed := strings.TrimRight(line, "\r\n")
+		switch {
+		case section == 0 && strings.HasPrefix(trimmed, markerLocal):
+			section = 1
+		case section == 1 && trimmed == markerSep:
+			section = 2
+		case section == 2 && strings.HasPrefix(trimmed, markerRemote):
+			section = 0
+		case section == 1:
+			l.WriteString(line)
+		case section == 2:
+			r.WriteString(line)
+		default:
+			l.WriteString(line)
+			r.WriteString(line)
+		}
+	}
+	// 冲突块之外没有内容时也返回非 nil，nil 表示该侧文件不存在
+	return append([]byte{}, l.Bytes()...), append([]byte{}, r.Bytes()...)
+}
+
+// HasConflictMarkers 判断内容中是否还有未处理的冲突标记
+func HasConflictMarkers(data []byte) bool {
+	for _, line := range splitLines(data) {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(trimmed, markerLocal) || strings.HasPrefix(trimmed, markerRemote) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveConflict 按指定方式解决一个冲突，清理旁路文件并从冲突记录中移除。
+// 解决后的内容与同步状态不同，下次 push 会作为本地修改上传
+func ResolveConflict(root string, c Conflict, resolution Resolution) error {
+	fullPath := filepath.Join(root, filepath.FromSlash(c.Path))
+
+	switch resolution {
+	case ResolveLocal, ResolveRemote:
+		local, remote, err := c.Sides(root)
+		if err != nil {
+			return fmt.Errorf("读取冲突内容失败: %w", err)
+		}
+		content := local
+		if resolution == ResolveRemote {
+			content = remote
+		}
+		// JSON 冲突的文件中已合并了两侧不冲突的修改，只在冲突的键路径上采用选定一侧的值
+		if c.Kind == ConflictContent && !c.Markers && !c.LocalInPlace && content != nil {
+			if merged, err := os.ReadFile(fullPath); err == nil {
+				if applied, ok := applyJSONPaths(merged, content, c.Locations); ok {
+					content = applied
+				}
+			}
+		}
+
+		if content == nil {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		} else if err := utils.WriteFile(fullPath, content, 0644); err != nil {
+			return err
+		}
+	case ResolveEdited:
+		if err := checkEdited(fullPath); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("未知的解决方式: %s", resolution)
+	}
+
+	for _, side := range []string{fullPath + LocalSuffix, fullPath + RemoteSuffix} {
+		if err := os.Remove(side); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return MarkResolved(root, c.Path)
+}
+
+// checkEdited 校验手动编辑后的文件：不能残留冲突标记，JSON 文件必须合法
+func checkEdited(fullPath string) error {
+	data, err := os.ReadFile(fullPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if HasConflictMarkers(data) {
+		return fmt.Errorf("%s 中仍有冲突标记", fullPath)
+	}
+	if strings.EqualFold(filepath.Ext(fullPath), ".json") && !json.Valid(data) {
+		return fmt.Errorf("%s 不是合法的 JSON", fullPath)
+	}
+	return nil
+}
+
+// MarkResolved 从冲突记录中移除指定文件，全部解决后 push 与 pull 不再被阻止
+func MarkResolved(root, path string) error {
+	conflicts, err := LoadConflicts(root)
+	if err != nil {
+		return err
+	}
+
+	remaining := conflicts[:0]
+	for _, c := range conflicts {
+		if c.Path != path {
+			remaining = append(remaining, c)
+		}
+	}
+	return SaveConflicts(root, remaining)
+}
@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveJSONConflictKeepsMergedChanges(t *testing.T) {
+	const (
+		base   = `{"title":"t","a":1,"b":1,"cols":[{"id":"c1","len":1},{"id":"c2","len":1}]}`
+		local  = `{"title":"local","a":1,"b":2,"cols":[{"id":"c1","len":5},{"id":"c2","len":1}]}`
+		remote = `{"title":"remote","a":2,"b":1,"cols":[{"id":"c1","len":9},{"id":"c2","len":3}]}`
+	)
+
+	tests := []struct {
+		name       string
+		resolution Resolution
+		want       string
+	}{
+		{"keep local", ResolveLocal,
+			`{"title":"local","a":2,"b":2,"cols":[{"id":"c1","len":5},{"id":"c2","len":3}]}`},
+		{"keep remote", ResolveRemote,
+			`{"title":"remote","a":2,"b":2,"cols":[{"id":"c1","len":9},{"id":"c2","len":3}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			merged := Merge3("meta/user.json", []byte(base), []byte(local), []byte(remote), "v2")
+			if merged.Markers {
+				t.Fatal("JSON conflict should not use markers")
+			}
+			if want := []string{"title", "cols[id=c1].len"}; !reflect.DeepEqual(merged.Conflicts, want) {
+				t.Fatalf("conflicts = %q, want %q", merged.Conflicts, want)
+			}
+
+			writeFile(t, root, "meta/user.json", string(merged.Content))
+			writeFile(t, root, "meta/user.json"+LocalSuffix, local)
+			writeFile(t, root, "meta/user.json"+RemoteSuffix, remote)
+			c := Conflict{Path: "meta/user.json", Kind: ConflictContent, Locations: merged.Conflicts}
+			if err := SaveConflicts(root, []Conflict{c}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ResolveConflict(root, c, tt.resolution); err != nil {
+				t.Fatalf("ResolveConflict: %v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(root, "meta", "user.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, got, tt.want)
+			for _, side := range []string{LocalSuffix, RemoteSuffix} {
+				if _, err := os.Stat(filepath.Join(root, "meta", "user.json"+side)); !os.IsNotExist(err) {
+					t.Errorf("%s side file should be removed, stat err = %v", side, err)
+				}
+			}
+			if conflicts, _ := LoadConflicts(root); len(conflicts) != 0 {
+				t.Errorf("conflicts left: %+v", conflicts)
+			}
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid JSON %q: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,269 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scalarRule 两侧都修改同一元数据字段时的取值规则
+type scalarRule int
+
+const (
+	ruleNone scalarRule = iota
+	// ruleNewer 修改时间取较新者
+	ruleNewer
+	// ruleHigher 版本号取较高者
+	ruleHigher
+)
+
+// timestampLayouts 修改时间字段可解析的格式
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// metadataRules 返回实体、页面、API、工作流文件中两侧必然都会修改的顶层元数据字段，
+// 按应用目录识别文件类型，其他文件没有规则，两侧修改不同即为冲突
+func metadataRules(filePath string) map[string]scalarRule {
+	dir, _, _ := strings.Cut(filePath, "/")
+	switch dir {
+	case "meta":
+		return map[string]scalarRule{"meta.version": ruleHigher}
+	case "page":
+		return map[string]scalarRule{"meta.version": ruleHigher, "page.version": ruleHigher}
+	case "api":
+		return map[string]scalarRule{"meta.version": ruleHigher, "meta.updatedAt": ruleNewer}
+	case "workflow":
+		return map[string]scalarRule{"meta.version": ruleHigher, "meta.updatedAt": ruleNewer, "meta.modifiedAt": ruleNewer}
+	}
+	return nil
+}
+
+// resolveScalar 按规则处理两侧都修改的元数据字段。无法按规则处理时返回 false，记为冲突
+func resolveScalar(rule scalarRule, l, r jsonValue) (jsonValue, bool) {
+	if !l.ok || !r.ok {
+		return jsonValue{}, false
+	}
+
+	var cmp int
+	switch rule {
+	case ruleNewer:
+		lt, lok := parseTimestamp(l.v)
+		rt, rok := parseTimestamp(r.v)
+		if !lok || !rok {
+			return jsonValue{}, false
+		}
+		cmp = lt.Compare(rt)
+	case ruleHigher:
+		var ok bool
+		if cmp, ok = compareVersions(scalarString(l.v), scalarString(r.v)); !ok {
+			return jsonValue{}, false
+		}
+	default:
+		return jsonValue{}, false
+	}
+
+	if cmp < 0 {
+		return r, true
+	}
+	return l, true
+}
+
+// parseTimestamp 解析修改时间字段，不是字符串或格式无法识别时返回 false
+func parseTimestamp(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func scalarString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	}
+	return ""
+}
+
+// compareVersions 比较 1.2.3、v2 这类点分数字版本号
+func compareVersions(a, b string) (int, bool) {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		var err error
+		if i < len(as) {
+			if x, err = strconv.Atoi(as[i]); err != nil {
+				return 0, false
+			}
+		}
+		if i < len(bs) {
+			if y, err = strconv.Atoi(bs[i]); err != nil {
+				return 0, false
+			}
+		}
+		if x != y {
+			if x < y {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// apiTagPattern 匹配 API 脚本文件头注释中的 @name、@path、@version 等标签行
+var apiTagPattern = regexp.MustCompile(`^\s*\*\s*@(\w+)`)
+
+// mergeAPIScript 合并 *.api.js：文件头 /** ... */ 中的标签逐个合并（@version 取较高者），
+// 脚本正文按行合并。文件头结构不一致时返回 false，整体按行合并
+func mergeAPIScript(base, local, remote []byte, remoteLabel string) (*MergeResult, bool) {
+	bh, bb, ok := splitAPIHeader(base)
+	if !ok {
+		return nil, false
+	}
+	lh, lb, ok := splitAPIHeader(local)
+	if !ok {
+		return nil, false
+	}
+	rh, rb, ok := splitAPIHeader(remote)
+	if !ok {
+		return nil, false
+	}
+
+	bt, bSkel, ok := apiHeaderTags(bh)
+	if !ok {
+		return nil, false
+	}
+	lt, lSkel, ok := apiHeaderTags(lh)
+	if !ok {
+		return nil, false
+	}
+	rt, rSkel, ok := apiHeaderTags(rh)
+	if !ok || !equalLines(bSkel, lSkel) || !equalLines(bSkel, rSkel) {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	result := &MergeResult{Markers: true}
+	line := 1
+	writeTag := func(tag string) {
+		b, l, r := bt[tag], lt[tag], rt[tag]
+		var out []string
+		switch {
+		case l == r, b == r:
+			out = lineSlice(l)
+		case b == l:
+			out = lineSlice(r)
+		case tag == "version" && l != "" && r != "":
+			out = lineSlice(l)
+			if cmp, ok := compareVersions(apiTagValue(l), apiTagValue(r)); ok && cmp < 0 {
+				out = lineSlice(r)
+			}
+		default:
+			result.Conflicts = append(result.Conflicts, strconv.Itoa(line))
+			line += writeConflict(&buf, lineSlice(l), lineSlice(r), remoteLabel)
+			return
+		}
+		for _, s := range out {
+			buf.WriteString(s)
+		}
+		line += len(out)
+	}
+
+	// 以本地标签顺序输出，远端新增的标签放在注释结尾之前
+	for i, s := range lh {
+		if i == len(lh)-1 {
+			for _, tag := range apiTagOrder(rh) {
+				if _, inLocal := lt[tag]; !inLocal {
+					writeTag(tag)
+				}
+			}
+		}
+		if m := apiTagPattern.FindStringSubmatch(s); m != nil {
+			writeTag(m[1])
+			continue
+		}
+		buf.WriteString(s)
+		line++
+	}
+
+	body := mergeText(bb, lb, rb, remoteLabel)
+	for _, c := range body.Conflicts {
+		n, _ := strconv.Atoi(c)
+		result.Conflicts = append(result.Conflicts, strconv.Itoa(n+line-1))
+	}
+	buf.Write(body.Content)
+
+	result.Content = buf.Bytes()
+	return result, true
+}
+
+// splitAPIHeader 拆出文件开头的 /** ... */ 注释行与其后的正文
+func splitAPIHeader(data []byte) ([]string, []byte, bool) {
+	lines := splitLines(data)
+	if len(lines) == 0 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "/**") {
+		return nil, nil, false
+	}
+	for i, s := range lines {
+		if strings.Contains(s, "*/") {
+			if i == 0 {
+				return nil, nil, false
+			}
+			return lines[:i+1], []byte(strings.Join(lines[i+1:], "")), true
+		}
+	}
+	return nil, nil, false
+}
+
+// apiHeaderTags 返回标签到整行的映射与去掉标签行后的其余行，同一标签出现多次时返回 false
+func apiHeaderTags(header []string) (map[string]string, []string, bool) {
+	tags := make(map[string]string)
+	var skeleton []string
+	for _, s := range header {
+		m := apiTagPattern.FindStringSubmatch(s)
+		if m == nil {
+			skeleton = append(skeleton, s)
+			continue
+		}
+		if _, dup := tags[m[1]]; dup {
+			return nil, nil, false
+		}
+		tags[m[1]] = s
+	}
+	return tags, skeleton, true
+}
+
+func apiTagOrder(header []string) []string {
+	var order []string
+	for _, s := range header {
+		if m := apiTagPattern.FindStringSubmatch(s); m != nil {
+			order = append(order, m[1])
+		}
+	}
+	return order
+}
+
+func apiTagValue(line string) string {
+	loc := apiTagPattern.FindStringIndex(line)
+	if loc == nil {
+		return ""
+	}
+	return strings.TrimSpace(line[loc[1]:])
+}
+
+func lineSlice(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
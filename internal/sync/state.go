@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/geelato/cli/pkg/utils"
+)
+
+// StateDir 应用目录下保存同步元数据的目录
+const StateDir = ".geelato"
+
+// StateFile 同步状态文件，记录上次同步的平台版本与各文件哈希
+var StateFile = filepath.Join(StateDir, "sync-state.json")
+
+// BaseDir 保存上次同步时各文件内容的目录，作为 pull 三方合并的共同祖先
+var BaseDir = filepath.Join(StateDir, "base")
+
+// DefaultBranch 未指定分支时使用的平台分支
+const DefaultBranch = "main"
+
+// State 上次成功同步时的状态
+type State struct {
+	// Branch 工作区当前跟踪的平台分支，为空表示 DefaultBranch
+	Branch string `json:"branch,omitempty"`
+	// MergeFrom 已合并到工作区、尚未推送的来源分支版本，随下一次 push 上传
+	MergeFrom  string            `json:"mergeFrom,omitempty"`
+	Version    string            `json:"version"`
+	LastSyncAt string            `json:"lastSyncAt"`
+	Files      map[string]string `json:"files"`
+}
+
+// LoadState 读取应用目录下的同步状态，文件不存在时返回空状态
+func LoadState(root string) (*State, error) {
+	path := filepath.Join(root, StateFile)
+	if !utils.Exists(path) {
+		return &State{Files: make(map[string]string)}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取同步状态失败: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析同步状态失败 %s: %w", path, err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]string)
+	}
+
+	return &state, nil
+}
+
+// Save 原子地写回同步状态，写入中断不会损坏已有的状态文件
+func (s *State) Save(root string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(filepath.Join(root, StateFile), data, 0644); err != nil {
+		return fmt.Errorf("保存同步状态失败: %w", err)
+	}
+	return nil
+}
+
+// Apply 将已同步的变更合并进状态，并记录新的平台版本
+func (s *State) Apply(changes []Change, version string) {
+	for _, change := range changes {
+		switch change.Type {
+		case ChangeAdded, ChangeModified:
+			s.Files[change.Path] = change.LocalHash
+		case ChangeDeleted:
+			delete(s.Files, change.Path)
+		}
+	}
+
+	if version != "" {
+		s.Version = version
+	}
+	s.LastSyncAt = time.Now().Format(time.RFC3339)
+}
+
+// ReadBase 读取文件上次同步时的内容，不存在时返回 nil
+func ReadBase(root, path string) []byte {
+	data, err := os.ReadFile(basePath(root, path))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// WriteBase 记录文件本次同步后的内容
+func WriteBase(root, path string, data []byte) error {
+	if err := utils.WriteFile(basePath(root, path), data, 0644); err != nil {
+		return fmt.Errorf("保存同步基线失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveBase 删除文件的同步基线
+func RemoveBase(root, path string) error {
+	if err := os.Remove(basePath(root, path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除同步基线失败: %w", err)
+	}
+	return nil
+}
+
+func basePath(root, path string) string {
+	return filepath.Join(root, BaseDir, filepath.FromSlash(path))
+}
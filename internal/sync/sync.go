@@ -1,408 +1,331 @@
 package sync
 
 import (
-	"archive/zip"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 
+	"github.com/geelato/cli/internal/app"
+	"github.com/geelato/cli/internal/config"
 	"github.com/geelato/cli/internal/file"
+	"github.com/geelato/cli/internal/platform"
+	gerrors "github.com/geelato/cli/pkg/errors"
 	"github.com/geelato/cli/pkg/logger"
 )
 
 type SyncService struct {
-	cwd    string
-	url    string
-	key    string
-	client *file.HTTPClient
+	cwd      string
+	endpoint *config.Endpoint
+	platform *platform.Client
+	// branch 命令行指定的分支，为空时使用工作区当前跟踪的分支
+	branch string
 }
 
-type DiffResult struct {
-	Added    []string `json:"added"`
-	Modified []string `json:"modified"`
-	Deleted  []string `json:"deleted"`
-}
-
-type FileRecord struct {
-	Path     string `json:"path"`
-	Hash     string `json:"hash"`
-	Type     string `json:"type"`
-}
-
-func NewSyncService(cwd, url, key string) (*SyncService, error) {
-	client, err := file.NewHTTPClient(url, key)
-	if err != nil {
-		return nil, err
+func NewSyncService(cwd string, endpoint *config.Endpoint) (*SyncService, error) {
+	if endpoint == nil || endpoint.URL == "" {
+		return nil, fmt.Errorf("API URL cannot be empty")
 	}
 
 	return &SyncService{
-		cwd:    cwd,
-		url:    url,
-		key:    key,
-		client: client,
+		cwd:      cwd,
+		endpoint: endpoint,
+		platform: platform.NewClientWithEndpoint(endpoint),
 	}, nil
 }
 
-func (s *SyncService) Push(ctx context.Context, message string) error {
-	logger.Info("Creating package...")
-
-	zipPath, err := s.createPackage()
-	if err != nil {
-		return fmt.Errorf("failed to create package: %w", err)
-	}
-	defer os.Remove(zipPath)
-
-	logger.Info("Uploading package...")
-	err = s.uploadPackage(ctx, zipPath, message)
-	if err != nil {
-		return fmt.Errorf("failed to upload package: %w", err)
-	}
-
-	return nil
+// SetBranch 指定本次操作的平台分支，操作成功后工作区改为跟踪该分支
+func (s *SyncService) SetBranch(branch string) {
+	s.branch = branch
 }
 
-func (s *SyncService) Pull(ctx context.Context) error {
-	logger.Info("Downloading package...")
-
-	zipPath, err := s.downloadPackage(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to download package: %w", err)
+// Branch 返回本次操作的平台分支：命令行指定 > 同步状态记录 > 配置 git.branch > DefaultBranch
+func (s *SyncService) Branch() string {
+	if s.branch != "" {
+		return s.branch
 	}
-	defer os.Remove(zipPath)
-
-	logger.Info("Extracting package...")
-	err = s.extractPackage(zipPath)
-	if err != nil {
-		return fmt.Errorf("failed to extract package: %w", err)
+	if state, err := LoadState(s.cwd); err == nil && state.Branch != "" {
+		return state.Branch
 	}
+	if cfg := config.Get(); cfg != nil && cfg.Git.Branch != "" {
+		return cfg.Git.Branch
+	}
+	return DefaultBranch
+}
 
-	return nil
+// Push 对比 .geelato/sync-state.json 计算变更集，只上传新增、修改和删除的文件。
+// 推送前先与平台检查冲突，存在冲突时返回 ErrSyncConflict 并在结果中列出冲突文件；
+// force 为 true 时跳过检查直接覆盖
+func (s *SyncService) Push(ctx context.Context, message string, force bool) (*PushResult, error) {
+	return s.push(ctx, message, force, nil)
 }
 
-func (s *SyncService) GetDiff() (*DiffResult, error) {
-	result := &DiffResult{
-		Added:    []string{},
-		Modified: []string{},
-		Deleted:  []string{},
-	}
+// PushPaths 只推送 paths 下的变更，冲突检查与 Push 相同。供 watch 在文件变化后增量推送，不扫描整个应用
+func (s *SyncService) PushPaths(ctx context.Context, message string, paths []string) (*PushResult, error) {
+	return s.push(ctx, message, false, paths)
+}
 
-	localFiles, err := s.scanLocalFiles()
-	if err != nil {
+// push 计算变更集并上传，paths 为 nil 时扫描整个应用
+func (s *SyncService) push(ctx context.Context, message string, force bool, paths []string) (*PushResult, error) {
+	if err := s.checkUnresolved(); err != nil {
 		return nil, err
 	}
 
-	resp, err := s.client.Get("/api/v1/apps/files")
+	state, err := LoadState(s.cwd)
 	if err != nil {
 		return nil, err
 	}
 
-	var remoteFiles []FileRecord
-	if err := json.Unmarshal(resp, &remoteFiles); err != nil {
-		return nil, err
+	var changes []Change
+	if paths == nil {
+		changes, err = DetectChanges(s.cwd, state)
+	} else {
+		changes, err = DetectPathChanges(s.cwd, state, paths)
 	}
-
-	localMap := make(map[string]string)
-	for _, f := range localFiles {
-		localMap[f.Path] = f.Hash
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect changes: %w", err)
 	}
-
-	remoteMap := make(map[string]string)
-	for _, f := range remoteFiles {
-		remoteMap[f.Path] = f.Hash
+	if len(changes) == 0 {
+		return &PushResult{Version: state.Version}, nil
 	}
 
-	for _, f := range localFiles {
-		if _, exists := remoteMap[f.Path]; !exists {
-			result.Added = append(result.Added, f.Path)
-		} else if localMap[f.Path] != remoteMap[f.Path] {
-			result.Modified = append(result.Modified, f.Path)
+	opts := s.pushOptions(message)
+	opts.Force = force
+
+	if !force {
+		if err := s.checkBranchHead(ctx, opts, state); err != nil {
+			return nil, err
 		}
-	}
 
-	for _, f := range remoteFiles {
-		if _, exists := localMap[f.Path]; !exists {
-			result.Deleted = append(result.Deleted, f.Path)
+		conflicts, err := CheckConflicts(ctx, s.platform, opts.AppID, opts.Branch, state, changes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check conflicts: %w", err)
+		}
+		if len(conflicts) > 0 {
+			result := &PushResult{Version: state.Version, Changes: changes, Conflicts: conflicts}
+			return result, gerrors.New(gerrors.ErrSyncConflict,
+				fmt.Sprintf("%d 个文件在平台版本 %s 之后已被修改，请先 pull 合并，或使用 --force 覆盖", len(conflicts), state.Version))
 		}
 	}
 
-	return result, nil
-}
+	logger.Infof("Uploading %d changed files...", len(changes))
 
-func (s *SyncService) createPackage() (string, error) {
-	tmpDir, err := ioutil.TempDir("", "geelato-push")
+	version, err := PushChanges(ctx, s.platform, s.cwd, state, changes, opts)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to upload changes: %w", err)
 	}
 
-	files, err := s.scanLocalFiles()
-	if err != nil {
-		return "", err
-	}
+	return &PushResult{Version: version, Changes: changes}, nil
+}
 
-	manifest := map[string]interface{}{
-		"createdAt": time.Now().Format(time.RFC3339),
-		"files":     files,
+func (s *SyncService) pushOptions(message string) PushOptions {
+	opts := PushOptions{
+		AppID:   s.endpoint.AppCode,
+		Branch:  s.Branch(),
+		Message: message,
 	}
 
-	manifestData, err := json.MarshalIndent(manifest, "", "  ")
-	if err != nil {
-		return "", err
+	if appConfig, err := app.LoadAppConfig(s.cwd); err == nil {
+		if appID := app.GetAppIDFromConfig(appConfig); appID != "" {
+			opts.AppID = appID
+		}
 	}
 
-	manifestPath := filepath.Join(tmpDir, "manifest.json")
-	if err := ioutil.WriteFile(manifestPath, manifestData, 0644); err != nil {
-		return "", err
+	if cfg := config.Get(); cfg != nil {
+		opts.Author = cfg.Git.User
 	}
 
-	baseName := filepath.Base(s.cwd)
-	zipPath := filepath.Join(tmpDir, baseName+".zip")
-
-	if err := s.zipDirectory(s.cwd, zipPath, files); err != nil {
-		return "", err
-	}
-
-	return zipPath, nil
+	return opts
 }
 
-func (s *SyncService) scanLocalFiles() ([]FileRecord, error) {
-	var files []FileRecord
+// Pull 下载平台上的最新版本，与本地修改三方合并。存在未解决的冲突时拒绝拉取
+func (s *SyncService) Pull(ctx context.Context) (*PullResult, error) {
+	return s.PullVersion(ctx, "")
+}
 
-	ignoreDirs := map[string]bool{
-		".git":     true,
-		"node_modules": true,
-		".idea":    true,
-		".vscode":  true,
-		"vendor":   true,
-		"__pycache__": true,
+// PullVersion 拉取指定的平台版本并与本地修改合并，version 为空时拉取当前分支的最新版本
+func (s *SyncService) PullVersion(ctx context.Context, version string) (*PullResult, error) {
+	if err := s.checkUnresolved(); err != nil {
+		return nil, err
 	}
 
-	err := filepath.Walk(s.cwd, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		relPath, err := filepath.Rel(s.cwd, path)
-		if err != nil {
-			return err
-		}
-
-		if relPath == "." || relPath == "" {
-			return nil
-		}
+	branch := s.Branch()
+	state, err := LoadState(s.cwd)
+	if err != nil {
+		return nil, err
+	}
 
-		dir := filepath.Dir(relPath)
-		if ignoreDirs[dir] || ignoreDirs[filepath.Base(dir)] {
-			if info.IsDir() {
-				return filepath.SkipDir
+	version, err = s.resolveVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	if version != "" && version == state.Version {
+		if state.Branch != branch {
+			state.Branch = branch
+			if err := state.Save(s.cwd); err != nil {
+				return nil, err
 			}
-			return nil
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		hash, err := file.HashFile(path)
-		if err != nil {
-			return err
 		}
+		return &PullResult{Version: state.Version}, nil
+	}
+	state.Branch = branch
 
-		files = append(files, FileRecord{
-			Path: relPath,
-			Hash: hash,
-			Type: getFileType(relPath),
-		})
+	remote, err := s.fetchPackage(ctx, version)
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
-	})
+	logger.Info("Merging remote changes...")
+	result, err := PullChanges(s.cwd, state, remote, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge package: %w", err)
+	}
 
-	return files, err
+	return result, nil
 }
 
-func (s *SyncService) zipDirectory(source, target string, files []FileRecord) error {
-	zipFile, err := os.Create(target)
+// Track 把工作区的当前内容记录为平台版本 version 的同步基线，供 clone 之后的 push、pull 检测变更与合并。
+// version 为空时查询当前分支上的最新版本
+func (s *SyncService) Track(ctx context.Context, version string) error {
+	version, err := s.resolveVersion(ctx, version)
 	if err != nil {
 		return err
 	}
-	defer zipFile.Close()
-
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
 
-	fileSet := make(map[string]bool)
-	for _, f := range files {
-		fileSet[f.Path] = true
+	files, err := ScanFiles(s.cwd)
+	if err != nil {
+		return fmt.Errorf("failed to scan files: %w", err)
 	}
-
-	err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		relPath, err := filepath.Rel(source, path)
-		if err != nil {
-			return err
-		}
-
-		if relPath == "." || relPath == "" {
-			return nil
-		}
-
-		if !fileSet[relPath] {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-
-		header.Name = relPath
-		header.Method = zip.Deflate
-
-		writer, err := zipWriter.CreateHeader(header)
+	for p := range files {
+		data, err := os.ReadFile(filepath.Join(s.cwd, filepath.FromSlash(p)))
 		if err != nil {
 			return err
 		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		f, err := os.Open(path)
-		if err != nil {
+		if err := WriteBase(s.cwd, p, data); err != nil {
 			return err
 		}
-		defer f.Close()
-
-		_, err = io.Copy(writer, f)
-		return err
-	})
+	}
 
-	return err
+	state := &State{Branch: s.branch, Version: version, Files: files}
+	state.Apply(nil, "")
+	return state.Save(s.cwd)
 }
 
-func (s *SyncService) uploadPackage(ctx context.Context, zipPath, message string) error {
-	zipFile, err := os.Open(zipPath)
+// resolveVersion version 为空时查询当前分支上的最新版本
+func (s *SyncService) resolveVersion(ctx context.Context, version string) (string, error) {
+	if version != "" {
+		return version, nil
+	}
+	opts := s.pushOptions("")
+	status, err := s.platform.GetSyncStatus(ctx, opts.AppID, opts.Branch)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to get remote version: %w", err)
 	}
-	defer zipFile.Close()
+	return status.Version, nil
+}
 
-	body := &file.UploadBody{
-		File:     zipFile,
-		Filename: filepath.Base(zipPath),
+// fetchPackage 下载指定版本的应用包并读出全部文件
+func (s *SyncService) fetchPackage(ctx context.Context, version string) (map[string][]byte, error) {
+	logger.Info("Downloading package...")
+	data, err := s.platform.DownloadAppPackageBytes(ctx, s.pushOptions("").AppID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download package: %w", err)
 	}
-
-	resp, err := s.client.Upload(ctx, "/api/v1/apps/upload", body)
+	ignore, err := file.LoadIgnore(s.cwd)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	logger.Debug(string(resp))
-	return nil
+	return ReadPackage(data, ignore)
 }
 
-func (s *SyncService) downloadPackage(ctx context.Context) (string, error) {
-	resp, err := s.client.GetWithContext(ctx, "/api/v1/apps/current")
-	if err != nil {
-		return "", err
+// checkBranchHead 推送到工作区未跟踪的分支时，变更集是相对当前版本计算的，
+// 只有目标分支仍停在该版本（或还没有版本）时才能直接推送
+func (s *SyncService) checkBranchHead(ctx context.Context, opts PushOptions, state *State) error {
+	if s.branch == "" || s.branch == state.Branch || state.Version == "" {
+		return nil
 	}
 
-	var metadata struct {
-		ID   string `json:"id"`
-		Path string `json:"path"`
+	status, err := s.platform.GetSyncStatus(ctx, opts.AppID, opts.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get remote version: %w", err)
 	}
-	if err := json.Unmarshal(resp, &metadata); err != nil {
-		return "", err
+	if status.Version != "" && status.Version != state.Version {
+		return gerrors.New(gerrors.ErrSyncConflict,
+			fmt.Sprintf("分支 %s 已在版本 %s，与工作区版本 %s 不同，请先 pull --branch %s 合并", opts.Branch, status.Version, state.Version, opts.Branch))
 	}
+	return nil
+}
 
-	tmpDir, err := ioutil.TempDir("", "geelato-pull")
+// checkUnresolved 存在上次 pull 遗留的冲突时返回 ErrSyncConflict
+func (s *SyncService) checkUnresolved() error {
+	conflicts, err := LoadConflicts(s.cwd)
 	if err != nil {
-		return "", err
+		return err
 	}
+	if len(conflicts) > 0 {
+		return gerrors.New(gerrors.ErrSyncConflict,
+			fmt.Sprintf("存在 %d 个未解决的冲突，请先运行 geelato sync resolve 解决", len(conflicts)))
+	}
+	return nil
+}
+
+// FileDiff 本地文件相对平台版本的差异。Type 以平台版本为基准：
+// 本地新增的文件为 added，本地没有而平台有的文件为 deleted
+type FileDiff struct {
+	Path   string
+	Type   ChangeType
+	Local  []byte
+	Remote []byte
+}
 
-	zipPath := filepath.Join(tmpDir, "app.zip")
-	zipFile, err := os.Create(zipPath)
+// Diff 下载平台版本（为空时为最新版本），与本地文件逐个比较内容。
+// paths 为相对应用根目录的文件或目录，为空时比较全部文件
+func (s *SyncService) Diff(ctx context.Context, version string, paths []string) (string, []FileDiff, error) {
+	version, err := s.resolveVersion(ctx, version)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	defer zipFile.Close()
 
-	_, err = s.client.Download(ctx, "/api/v1/apps/download", zipFile)
+	remote, err := s.fetchPackage(ctx, version)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return zipPath, nil
-}
-
-func (s *SyncService) extractPackage(zipPath string) error {
-	reader, err := zip.OpenReader(zipPath)
+	local, err := ScanFiles(s.cwd)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-	defer reader.Close()
-
-	for _, zipEntry := range reader.File {
-		path := filepath.Join(s.cwd, zipEntry.Name)
 
-		if zipEntry.FileInfo().IsDir() {
-			os.MkdirAll(path, 0755)
+	var diffs []FileDiff
+	for _, change := range Diff(packageHashes(remote), local) {
+		if !matchPaths(change.Path, paths) {
 			continue
 		}
 
-		os.MkdirAll(filepath.Dir(path), 0755)
-
-		rc, err := zipEntry.Open()
-		if err != nil {
-			return err
-		}
-
-		data, err := ioutil.ReadAll(rc)
-		rc.Close()
-		if err != nil {
-			return err
-		}
-
-		if err := ioutil.WriteFile(path, data, 0644); err != nil {
-			return err
+		diff := FileDiff{Path: change.Path, Type: change.Type, Remote: remote[change.Path]}
+		if change.Type != ChangeDeleted {
+			data, err := os.ReadFile(filepath.Join(s.cwd, filepath.FromSlash(change.Path)))
+			if err != nil {
+				return "", nil, err
+			}
+			diff.Local = data
 		}
+		diffs = append(diffs, diff)
 	}
 
-	return nil
+	return version, diffs, nil
 }
 
-func getFileType(path string) string {
-	ext := filepath.Ext(path)
-	switch ext {
-	case ".json":
-		return "model"
-	case ".js":
-		return "api"
-	case ".xml", ".bpmn":
-		return "workflow"
-	default:
-		return "other"
+// matchPaths 判断文件是否位于指定的文件或目录之下
+func matchPaths(file string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
 	}
+	for _, p := range paths {
+		if p == "." || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
 }
-
-type UploadResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	ID      string `json:"id"`
-}
-
-var (
-	ErrNotConfigured = errors.New("API URL not configured")
-	ErrUnauthorized  = errors.New("Unauthorized")
-)
@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/geelato/cli/internal/config"
+)
+
+// TestCheckoutAfterTrack 克隆后记录的基线与工作区一致，checkout 不应把文件视为未推送的修改
+func TestCheckoutAfterTrack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	v1 := map[string]string{
+		"geelato.json":     `{"meta":{"appId":"app1"}}`,
+		"page/home.json":   `{"title":"v1"}`,
+		"page/remove.json": `{"title":"v1 only"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/cli/app/download" || r.URL.Query().Get("version") != "v1" {
+			http.NotFound(w, r)
+			return
+		}
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range v1 {
+			f, _ := zw.Create(name)
+			f.Write([]byte(content))
+		}
+		zw.Close()
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	writeFile(t, root, "geelato.json", `{"meta":{"appId":"app1"}}`)
+	writeFile(t, root, "page/home.json", `{"title":"v2"}`)
+	writeFile(t, root, "page/added.json", `{"title":"v2 only"}`)
+
+	svc, err := NewSyncService(root, &config.Endpoint{URL: srv.URL, AppCode: "app1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Track(context.Background(), "v2"); err != nil {
+		t.Fatalf("Track: %v", err)
+	}
+
+	state, err := LoadState(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Version != "v2" || len(state.Files) != 3 {
+		t.Fatalf("state = %+v, want version v2 with 3 files", state)
+	}
+	if got := string(ReadBase(root, "page/home.json")); got != `{"title":"v2"}` {
+		t.Fatalf("base of page/home.json = %q", got)
+	}
+
+	if _, err := svc.Checkout(context.Background(), "v1"); err != nil {
+		t.Fatalf("Checkout after Track: %v", err)
+	}
+	for name, content := range v1 {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "page", "added.json")); !os.IsNotExist(err) {
+		t.Errorf("page/added.json should be deleted by checkout, stat err = %v", err)
+	}
+}
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
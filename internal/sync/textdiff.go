@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffLine 统一格式差异中的一行，Op 为 ' '、'-' 或 '+'
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// Hunk 统一格式差异中的一个变更块
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []DiffLine
+}
+
+// Header 返回 @@ -a,b +c,d @@ 形式的块头
+func (h *Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// UnifiedDiff 计算 a 到 b 的行级差异，每个变更块前后保留 context 行上下文
+func UnifiedDiff(a, b []byte, context int) []Hunk {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var changes []int
+	for i, op := range ops {
+		if op.Op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var hunks []Hunk
+	for start := 0; start < len(changes); {
+		end := start
+		for end+1 < len(changes) && changes[end+1]-changes[end] <= 2*context+1 {
+			end++
+		}
+
+		from := changes[start] - context
+		if from < 0 {
+			from = 0
+		}
+		to := changes[end] + context + 1
+		if to > len(ops) {
+			to = len(ops)
+		}
+
+		hunk := Hunk{OldStart: 1, NewStart: 1}
+		for _, op := range ops[:from] {
+			if op.Op != '+' {
+				hunk.OldStart++
+			}
+			if op.Op != '-' {
+				hunk.NewStart++
+			}
+		}
+		for _, op := range ops[from:to] {
+			if op.Op != '+' {
+				hunk.OldLines++
+			}
+			if op.Op != '-' {
+				hunk.NewLines++
+			}
+			hunk.Lines = append(hunk.Lines, op)
+		}
+		// 与 diff -u 一致，空的一侧从变更位置之前一行算起
+		if hunk.OldLines == 0 {
+			hunk.OldStart--
+		}
+		if hunk.NewLines == 0 {
+			hunk.NewStart--
+		}
+
+		hunks = append(hunks, hunk)
+		start = end + 1
+	}
+
+	return hunks
+}
+
+// diffLines 基于最长公共子序列生成编辑脚本，行内容去掉换行符
+func diffLines(a, b []string) []DiffLine {
+	if len(a)*len(b) > mergeLineLimit {
+		ops := make([]DiffLine, 0, len(a)+len(b))
+		for _, s := range a {
+			ops = append(ops, DiffLine{Op: '-', Text: trimEOL(s)})
+		}
+		for _, s := range b {
+			ops = append(ops, DiffLine{Op: '+', Text: trimEOL(s)})
+		}
+		return ops
+	}
+
+	match := matchLines(a, b)
+	ops := make([]DiffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && match[i] < 0:
+			ops = append(ops, DiffLine{Op: '-', Text: trimEOL(a[i])})
+			i++
+		case i < len(a) && match[i] == j:
+			ops = append(ops, DiffLine{Op: ' ', Text: trimEOL(a[i])})
+			i++
+			j++
+		default:
+			ops = append(ops, DiffLine{Op: '+', Text: trimEOL(b[j])})
+			j++
+		}
+	}
+	return ops
+}
+
+func trimEOL(s string) string {
+	return strings.TrimRight(s, "\r\n")
+}
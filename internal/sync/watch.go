@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geelato/cli/internal/platform"
+	"github.com/geelato/cli/pkg/logger"
+)
+
+// PullRemote 把当前分支上的新版本应用到本地，供 watch --bidirectional 使用。
+// 与 Pull 不同，本地修改过的文件从不被改写：两侧都修改的文件记为冲突，远端内容写入 .remote 旁路文件。
+// 已有未解决的冲突时也会执行。平台版本与工作区相同时返回 nil
+func (s *SyncService) PullRemote(ctx context.Context) (*PullResult, error) {
+	state, err := LoadState(s.cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.resolveVersion(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if version == "" || version == state.Version {
+		return nil, nil
+	}
+
+	remote, err := s.fetchPackage(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	state.Branch = s.Branch()
+
+	result, err := ApplyRemoteChanges(s.cwd, state, remote, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply remote changes: %w", err)
+	}
+	return result, nil
+}
+
+// WatchRemote 监听当前分支上的新版本，每次可能有新版本时调用 onChange，直到 ctx 结束。
+// 优先订阅平台的变更通知，平台不支持或连接失败时改为每隔 interval 轮询一次
+func (s *SyncService) WatchRemote(ctx context.Context, interval time.Duration, onChange func()) {
+	opts := s.pushOptions("")
+
+	for ctx.Err() == nil {
+		connected := time.Now()
+		err := s.platform.WatchEvents(ctx, opts.AppID, opts.Branch, func(status platform.SyncStatus) {
+			logger.Debugf("Platform published version %s on branch %s", status.Version, status.Branch)
+			onChange()
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Infof("Platform change notifications unavailable (%v), checking for remote changes every %s", err, interval)
+			break
+		}
+		// 连接被平台正常关闭时重新订阅，短时间内反复断开说明平台并不保持连接，改为轮询
+		if time.Since(connected) < interval {
+			logger.Infof("Platform closed the change notification stream, checking for remote changes every %s", interval)
+			break
+		}
+		onChange()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			onChange()
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/geelato/cli/internal/auth"
+	"github.com/geelato/cli/pkg/logger"
+)
+
+// Credentials 请求使用的认证信息：geelato login 保存的令牌优先，其次为 API Key
+type Credentials struct {
+	server string
+	apiKey string
+
+	mu        sync.Mutex
+	token     *auth.Token
+	refreshMu sync.Mutex
+}
+
+// NewCredentials 创建认证信息。apiKey 为空时从凭据存储加载该服务器的令牌
+func NewCredentials(server, apiKey string) *Credentials {
+	c := &Credentials{server: server, apiKey: apiKey}
+	if apiKey == "" && server != "" {
+		c.token = auth.LoadToken(server)
+	}
+	return c
+}
+
+// Token 返回当前令牌，未登录时为 nil
+func (c *Credentials) Token() *auth.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SetToken 替换当前令牌
+func (c *Credentials) SetToken(token *auth.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// SetAccessToken 仅替换访问令牌，保留刷新令牌等其他信息
+func (c *Credentials) SetAccessToken(accessToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == nil {
+		c.token = &auth.Token{Server: auth.NormalizeServer(c.server)}
+	} else {
+		token := *c.token
+		c.token = &token
+	}
+	c.token.AccessToken = accessToken
+}
+
+func (c *Credentials) canRefresh() bool {
+	token := c.Token()
+	return token != nil && token.RefreshToken != ""
+}
+
+func (c *Credentials) apply(req *http.Request) {
+	if token := c.Token(); token != nil && token.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	} else if c.apiKey != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+}
+
+// Auth 为请求附加认证信息。令牌过期或平台返回 401 时，若存在刷新令牌则调用 refresh 刷新并重试一次
+func Auth(creds *Credentials, refresh func(ctx context.Context) error) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if isNoAuth(req) {
+				return next.RoundTrip(req)
+			}
+
+			ctx := req.Context()
+			if creds.canRefresh() && creds.Token().Expired() {
+				if err := refresh(ctx); err != nil {
+					logger.Debugf("刷新访问令牌失败: %v", err)
+				}
+			}
+
+			r := req.Clone(ctx)
+			creds.apply(r)
+			resp, err := next.RoundTrip(r)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized || !creds.canRefresh() {
+				return resp, err
+			}
+			if req.Body != nil && req.GetBody == nil {
+				return resp, nil
+			}
+
+			if err := refresh(ctx); err != nil {
+				logger.Debugf("刷新访问令牌失败: %v", err)
+				return resp, nil
+			}
+			drain(resp)
+
+			r, err = rewind(req, 2)
+			if err != nil {
+				return nil, err
+			}
+			creds.apply(r)
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+// refreshToken 使用刷新令牌换取新的访问令牌，并写回凭据存储
+func (c *Client) refreshToken(ctx context.Context) error {
+	creds := c.credentials
+	creds.refreshMu.Lock()
+	defer creds.refreshMu.Unlock()
+
+	old := creds.Token()
+	if old == nil || old.RefreshToken == "" {
+		return fmt.Errorf("没有可用的刷新令牌")
+	}
+
+	resp, err := c.Do(ctx, Request{
+		Method: http.MethodPost,
+		Path:   "/api/cli/auth/refresh",
+		Body: map[string]interface{}{
+			"refreshToken": old.RefreshToken,
+		},
+		NoAuth: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var result auth.TokenResponse
+	if err := resp.Decode(&result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.RefreshToken == "" {
+		result.RefreshToken = old.RefreshToken
+	}
+	if result.Username == "" {
+		result.Username = old.Username
+	}
+
+	token, err := result.Token(c.baseURL)
+	if err != nil {
+		return err
+	}
+	creds.SetToken(token)
+
+	store, err := auth.DefaultStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Save(token); err != nil {
+		logger.Warn("保存刷新后的令牌失败: %v", err)
+	}
+
+	logger.Debugf("访问令牌已刷新: %s", token.Server)
+	return nil
+}
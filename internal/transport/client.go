@@ -0,0 +1,324 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/geelato/cli/internal/config"
+	"github.com/geelato/cli/pkg/retry"
+)
+
+// DefaultTimeout 未配置超时时单次请求的超时时间
+const DefaultTimeout = 30 * time.Second
+
+// Client 访问 Geelato 平台的统一 HTTP 客户端。
+// 请求依次经过追踪、认证、重试、日志中间件，最终由底层 http.Transport 发出
+type Client struct {
+	baseURL     string
+	timeout     time.Duration
+	credentials *Credentials
+	base        http.RoundTripper
+	middlewares []Middleware
+	// err 创建底层传输时的配置错误，每次请求都会返回该错误
+	err error
+
+	mu      sync.Mutex
+	headers map[string]string
+	client  *http.Client
+}
+
+// Options 创建客户端的参数
+type Options struct {
+	BaseURL     string
+	Timeout     time.Duration
+	Retry       retry.Policy
+	Credentials *Credentials
+	// Transport 底层传输，为空时使用 http.DefaultTransport 的副本
+	Transport http.RoundTripper
+}
+
+// Request 描述一次平台请求
+type Request struct {
+	Method string
+	Path   string
+	Query  map[string]string
+	// Body 以 JSON 序列化后发送
+	Body interface{}
+	// RawBody 原样发送，优先于 Body。实现 io.Seeker 时请求可以重试
+	RawBody     io.Reader
+	ContentType string
+	Headers     map[string]string
+	// Timeout 覆盖客户端的默认超时，包含重试等待时间
+	Timeout time.Duration
+	// Idempotent 标记非幂等方法的请求可以安全重试
+	Idempotent bool
+	// IdempotencyKey 非空时作为 Idempotency-Key 请求头发送，服务端据此去重，请求可以安全重试
+	IdempotencyKey string
+	// NoAuth 不附加认证信息，也不在 401 时刷新令牌
+	NoAuth bool
+}
+
+// Response 平台响应
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+// Decode 解析响应体，自动拆开 {code, data} 包装
+func (r *Response) Decode(v interface{}) error {
+	return Decode(r.Body, v)
+}
+
+// New 使用默认中间件链创建客户端
+func New(opts Options) *Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	base := opts.Transport
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	c := &Client{
+		baseURL:     strings.TrimSuffix(opts.BaseURL, "/"),
+		timeout:     timeout,
+		credentials: opts.Credentials,
+		base:        base,
+		headers:     make(map[string]string),
+	}
+	if c.credentials == nil {
+		c.credentials = &Credentials{server: c.baseURL}
+	}
+
+	c.Use(
+		Tracing(),
+		Auth(c.credentials, c.refreshToken),
+		Retry(opts.Retry),
+		Logging(),
+	)
+	return c
+}
+
+// NewWithEndpoint 按解析后的平台地址创建客户端，使用其中的代理与 TLS 配置。
+// 未配置 API Key 时使用 geelato login 保存的令牌
+func NewWithEndpoint(ep *config.Endpoint) *Client {
+	opts := Options{
+		BaseURL:     ep.URL,
+		Timeout:     time.Duration(ep.Timeout) * time.Second,
+		Retry:       ep.Retry.Policy(),
+		Credentials: NewCredentials(ep.URL, ep.Key),
+	}
+
+	httpTransport, err := NewHTTPTransport(ep)
+	if err == nil {
+		opts.Transport = httpTransport
+	}
+
+	c := New(opts)
+	c.err = err
+	return c
+}
+
+// Use 追加中间件。先添加的中间件位于外层，后添加的更靠近底层传输
+func (c *Client) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.middlewares = append(c.middlewares, middlewares...)
+
+	rt := c.base
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+	c.client = &http.Client{Transport: rt}
+}
+
+// BaseURL 返回平台地址
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Credentials 返回客户端使用的认证信息
+func (c *Client) Credentials() *Credentials {
+	return c.credentials
+}
+
+// SetHeader 设置每个请求都携带的请求头
+func (c *Client) SetHeader(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.headers[key] = value
+}
+
+// Do 发送请求并读取完整响应体。状态码不在 2xx 范围时返回映射后的错误，同时返回响应
+func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, networkError(err, req.Method, resp.Request.URL.String())
+	}
+
+	response := &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header.Clone(),
+		Body:       body,
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return response, statusError(resp.StatusCode, body)
+	}
+	return response, nil
+}
+
+// Stream 发送请求并返回未读取的响应，适用于下载等大响应体，调用方负责关闭 Body
+func (c *Client) Stream(ctx context.Context, req Request) (*http.Response, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, statusError(resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+func (c *Client) send(ctx context.Context, req Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	httpReq, err := c.newRequest(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	c.mu.Lock()
+	client := c.client
+	c.mu.Unlock()
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		cancel()
+		return nil, networkError(err, req.Method, httpReq.URL.String())
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, req Request) (*http.Request, error) {
+	target := c.baseURL + req.Path
+	if len(req.Query) > 0 {
+		values := url.Values{}
+		for k, v := range req.Query {
+			values.Set(k, v)
+		}
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + values.Encode()
+	}
+
+	var body io.Reader
+	contentType := req.ContentType
+	seeker, _ := req.RawBody.(io.ReadSeeker)
+	switch {
+	case req.RawBody != nil:
+		// 避免 net/http 发送后关闭调用方的文件，重试时还需要回到开头重新读取
+		body = io.NopCloser(req.RawBody)
+	case req.Body != nil:
+		data, err := json.Marshal(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		body = bytes.NewReader(data)
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	if seeker != nil {
+		size, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		httpReq.ContentLength = size
+		httpReq.GetBody = func() (io.ReadCloser, error) {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(seeker), nil
+		}
+	}
+
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	c.mu.Lock()
+	for k, v := range c.headers {
+		httpReq.Header.Set(k, v)
+	}
+	c.mu.Unlock()
+
+	if req.IdempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.IdempotencyKey)
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	if req.Idempotent {
+		httpReq = httpReq.WithContext(withIdempotent(httpReq.Context()))
+	}
+	if req.NoAuth {
+		httpReq = httpReq.WithContext(withoutAuth(httpReq.Context()))
+	}
+
+	return httpReq, nil
+}
+
+// cancelBody 在响应体关闭时释放请求的超时上下文
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	gerrors "github.com/geelato/cli/pkg/errors"
+)
+
+// envelope Geelato 平台的统一响应结构
+type envelope struct {
+	Code    *int            `json:"code"`
+	Data    json.RawMessage `json:"data"`
+	Message string          `json:"message"`
+	Msg     string          `json:"msg"`
+}
+
+// Decode 解析平台响应到 v。
+// 兼容 {code, data} 包装结构、data 内再以 {format, data} 包装一层的结构，以及直接返回的数据。
+// code 不为 0 或 200 时返回 ErrPlatformResponse 错误
+func Decode(body []byte, v interface{}) error {
+	data, err := Unwrap(body)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Unwrap 拆开响应包装，返回业务数据的原始 JSON
+func Unwrap(body []byte) (json.RawMessage, error) {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil || env.Code == nil {
+		return body, nil
+	}
+
+	message := env.Message
+	if message == "" {
+		message = env.Msg
+	}
+
+	if code := *env.Code; code != 0 && code != 200 {
+		if message == "" {
+			message = "未知错误"
+		}
+		return nil, gerrors.Wrap(fmt.Errorf("平台返回错误码 %d: %s", code, message), gerrors.ErrPlatformResponse, message)
+	}
+
+	// 只有 code 没有 data 与 message 时不是包装结构，按原始数据处理
+	if env.Data == nil && message == "" {
+		return body, nil
+	}
+
+	var nested struct {
+		Format string          `json:"format"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(env.Data, &nested); err == nil && nested.Format != "" && nested.Data != nil {
+		return nested.Data, nil
+	}
+
+	return env.Data, nil
+}
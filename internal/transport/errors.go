@@ -0,0 +1,90 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	gerrors "github.com/geelato/cli/pkg/errors"
+)
+
+// StatusError 平台返回的非 2xx 响应
+type StatusError struct {
+	StatusCode int
+	Message    string
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("服务器返回 %d: %s", e.StatusCode, e.Message)
+}
+
+// StatusCode 返回错误对应的 HTTP 状态码，非平台响应错误时返回 0
+func StatusCode(err error) int {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode
+	}
+	return 0
+}
+
+// statusError 将非 2xx 响应映射为 pkg/errors 错误码
+func statusError(statusCode int, body []byte) error {
+	se := &StatusError{
+		StatusCode: statusCode,
+		Message:    responseMessage(body),
+		Body:       body,
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return gerrors.Wrap(se, gerrors.ErrPlatformAuth, se.Message, "请运行 'geelato login' 重新登录")
+	case http.StatusForbidden:
+		return gerrors.Wrap(se, gerrors.ErrPlatformAuth, "权限不足: "+se.Message)
+	case http.StatusNotFound:
+		return gerrors.Wrap(se, gerrors.ErrNetworkResponse, "资源不存在: "+se.Message)
+	case http.StatusConflict:
+		return gerrors.Wrap(se, gerrors.ErrSyncConflict, se.Message)
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return gerrors.Wrap(se, gerrors.ErrNetworkTimeout, se.Message)
+	default:
+		return gerrors.Wrap(se, gerrors.ErrNetworkResponse, fmt.Sprintf("服务器错误 %d: %s", statusCode, se.Message))
+	}
+}
+
+// networkError 将传输层错误映射为 pkg/errors 错误码，用户主动取消时原样返回
+func networkError(err error, method, url string) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	detail := fmt.Sprintf("%s %s", method, url)
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return gerrors.Wrap(err, gerrors.ErrNetworkTimeout, detail)
+	}
+	return gerrors.Wrap(err, gerrors.ErrNetworkUnavailable, detail)
+}
+
+func responseMessage(body []byte) string {
+	var resp struct {
+		Message string `json:"message"`
+		Msg     string `json:"msg"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil {
+		switch {
+		case resp.Message != "":
+			return resp.Message
+		case resp.Msg != "":
+			return resp.Msg
+		case resp.Error != "":
+			return resp.Error
+		}
+	}
+	return "未知错误"
+}
@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/geelato/cli/pkg/logger"
+	"github.com/geelato/cli/pkg/retry"
+	"github.com/geelato/cli/pkg/utils"
+)
+
+// RoundTripFunc 将函数适配为 http.RoundTripper
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware 包装下一层 RoundTripper，在请求链上插入认证、日志、重试等处理
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+type contextKey int
+
+const (
+	idempotentKey contextKey = iota
+	noAuthKey
+)
+
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey, true)
+}
+
+func withoutAuth(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noAuthKey, true)
+}
+
+func isIdempotent(req *http.Request) bool {
+	if retry.IdempotentMethod(req.Method) || req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+	v, _ := req.Context().Value(idempotentKey).(bool)
+	return v
+}
+
+func isNoAuth(req *http.Request) bool {
+	v, _ := req.Context().Value(noAuthKey).(bool)
+	return v
+}
+
+// Tracing 为请求生成 X-Request-ID，重试时保持不变，便于与平台日志对照
+func Tracing() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Request-ID") != "" {
+				return next.RoundTrip(req)
+			}
+
+			id, err := utils.RandomHex(8)
+			if err != nil {
+				return next.RoundTrip(req)
+			}
+
+			req = req.Clone(req.Context())
+			req.Header.Set("X-Request-ID", id)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Logging 在调试日志中记录每次实际发出的请求及耗时
+func Logging() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			id := req.Header.Get("X-Request-ID")
+			if err != nil {
+				logger.Debugf("%s %s [%s] 失败 (%v): %v", req.Method, req.URL.Redacted(), id, elapsed, err)
+				return nil, err
+			}
+			logger.Debugf("%s %s [%s] %d (%v)", req.Method, req.URL.Redacted(), id, resp.StatusCode, elapsed)
+			return resp, nil
+		})
+	}
+}
+
+// Retry 对幂等请求在网络错误和 408/429/5xx 网关类错误时按策略退避重试。
+// 幂等请求包括 GET/PUT/DELETE 等方法、携带 Idempotency-Key 或标记为 Idempotent 的请求
+func Retry(policy retry.Policy) Middleware {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if maxAttempts == 1 || !isIdempotent(req) || (req.Body != nil && req.GetBody == nil) {
+				return next.RoundTrip(req)
+			}
+
+			ctx := req.Context()
+			var resp *http.Response
+			err := policy.Do(ctx, func(attempt int) error {
+				r, err := rewind(req, attempt)
+				if err != nil {
+					return err
+				}
+
+				resp, err = next.RoundTrip(r)
+				if err != nil {
+					if ctx.Err() == nil {
+						return retry.Retryable(err, 0)
+					}
+					return err
+				}
+
+				// 最后一次尝试的响应原样返回，由调用方按状态码处理
+				if attempt < maxAttempts && retry.RetryableStatus(resp.StatusCode) {
+					after := retry.ParseRetryAfter(resp.Header.Get("Retry-After"))
+					err := fmt.Errorf("服务端返回 %d", resp.StatusCode)
+					drain(resp)
+					resp = nil
+					return retry.Retryable(err, after)
+				}
+				return nil
+			})
+			if resp != nil {
+				return resp, nil
+			}
+			return nil, err
+		})
+	}
+}
+
+// rewind 为重试准备请求，首次尝试直接使用原请求
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.Body == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// drain 读尽并关闭响应体，使连接可以复用
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}
@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/geelato/cli/internal/config"
+	gerrors "github.com/geelato/cli/pkg/errors"
+	"github.com/geelato/cli/pkg/logger"
+)
+
+// insecureWarned 记录已提示过跳过证书校验的服务器，同一进程内只提示一次
+var insecureWarned sync.Map
+
+// NewHTTPTransport 按平台地址的代理与 TLS 配置创建底层传输
+func NewHTTPTransport(ep *config.Endpoint) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := proxyFunc(ep.Proxy, ep.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+	t.Proxy = proxy
+
+	tlsConfig, err := tlsClientConfig(ep.TLS)
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsConfig
+
+	if ep.TLS.InsecureSkipVerify {
+		if _, warned := insecureWarned.LoadOrStore(ep.URL, true); !warned {
+			logger.Warn("!!! 已关闭 TLS 证书校验 (api.tls.insecureSkipVerify)，与 %s 的连接可能被中间人窃听或篡改，请仅在测试环境使用，生产环境请改用 api.tls.caFile 配置自签名证书", ep.URL)
+		}
+	}
+
+	return t, nil
+}
+
+// proxyFunc 返回代理选择函数。未配置代理时沿用 HTTP_PROXY、HTTPS_PROXY、NO_PROXY 环境变量
+func proxyFunc(proxy, noProxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		if noProxy == "" {
+			return http.ProxyFromEnvironment, nil
+		}
+		return func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL, noProxy) {
+				return nil, nil
+			}
+			return http.ProxyFromEnvironment(req)
+		}, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, gerrors.New(gerrors.ErrConfigInvalid, fmt.Sprintf("代理地址无效: %s", proxy))
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, gerrors.New(gerrors.ErrConfigInvalid, fmt.Sprintf("不支持的代理协议 %s，可选 http、https、socks5", proxyURL.Scheme))
+	}
+
+	if noProxy == "" {
+		noProxy = os.Getenv("NO_PROXY")
+		if noProxy == "" {
+			noProxy = os.Getenv("no_proxy")
+		}
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// bypassProxy 判断目标地址是否命中 NO_PROXY 规则。
+// 规则以逗号分隔：* 匹配全部；example.com 与 .example.com 匹配该域名及子域名；
+// 也支持 IP、CIDR，以及带端口的 host:port
+func bypassProxy(target *url.URL, noProxy string) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	ip := net.ParseIP(host)
+
+	for _, rule := range strings.Split(noProxy, ",") {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		if rule == "" {
+			continue
+		}
+		if rule == "*" {
+			return true
+		}
+
+		if _, cidr, err := net.ParseCIDR(rule); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		ruleHost, rulePort := rule, ""
+		if h, p, err := net.SplitHostPort(rule); err == nil {
+			ruleHost, rulePort = h, p
+		}
+		if rulePort != "" && rulePort != port {
+			continue
+		}
+
+		ruleHost = strings.TrimPrefix(ruleHost, "*")
+		if ruleIP := net.ParseIP(ruleHost); ruleIP != nil {
+			if ip != nil && ruleIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		domain := strings.TrimPrefix(ruleHost, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsClientConfig 加载自定义 CA 与客户端证书
+func tlsClientConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(expandHome(cfg.CAFile))
+		if err != nil {
+			return nil, gerrors.Wrap(err, gerrors.ErrConfigInvalid, "读取 CA 证书失败: "+cfg.CAFile)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, gerrors.New(gerrors.ErrConfigInvalid, "CA 证书中没有有效的 PEM 证书: "+cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, gerrors.New(gerrors.ErrConfigInvalid, "客户端证书需要同时配置 api.tls.certFile 与 api.tls.keyFile")
+		}
+
+		cert, err := tls.LoadX509KeyPair(expandHome(cfg.CertFile), expandHome(cfg.KeyFile))
+		if err != nil {
+			return nil, gerrors.Wrap(err, gerrors.ErrConfigInvalid, "加载客户端证书失败")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
@@ -2,174 +2,305 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/geelato/cli/internal/file"
 	"github.com/geelato/cli/pkg/logger"
 )
 
+// 默认的合并等待时间与轮询间隔
+const (
+	DefaultDebounce = 300 * time.Millisecond
+	DefaultInterval = 2 * time.Second
+)
+
+// Options 监听参数
+type Options struct {
+	// Debounce 最后一次变化后等待的时间，期间的变化合并为一批交给 Handler
+	Debounce time.Duration
+	// Interval 轮询间隔，只在轮询模式下使用
+	Interval time.Duration
+	// Poll 不使用文件系统通知，直接轮询
+	Poll bool
+}
+
+// Handler 处理一批变化。paths 为相对应用根目录的文件或目录（/ 分隔），已去重排序；
+// 删除的路径同样会出现，目录表示其下的内容都可能有变化
+type Handler func(ctx context.Context, paths []string)
+
+// Watcher 监听应用目录的变化：优先使用文件系统通知（Linux 上为 inotify），
+// 不可用时退回按修改时间与大小轮询。.geelatoignore 忽略的路径不会触发变化
 type Watcher struct {
-	rootDir  string
-	interval time.Duration
-	lastHash map[string]string
-	client   *file.HTTPClient
+	rootDir string
+	opts    Options
+	ignore  *file.Ignore
 }
 
-type WatchEvent struct {
-	Type   string
-	Path   string
-	RelPath string
+// fileStat 轮询时用于判断文件是否变化的信息
+type fileStat struct {
+	size    int64
+	modTime time.Time
 }
 
-func NewWatcher(rootDir string) (*Watcher, error) {
+func NewWatcher(rootDir string, opts Options) (*Watcher, error) {
 	_, err := os.Stat(rootDir)
 	if err != nil {
 		return nil, err
 	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+
+	ignore, err := file.LoadIgnore(rootDir)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Watcher{
-		rootDir:  rootDir,
-		interval: 2 * time.Second,
-		lastHash: make(map[string]string),
+		rootDir: rootDir,
+		opts:    opts,
+		ignore:  ignore,
 	}, nil
 }
 
-func (w *Watcher) SetClient(client *file.HTTPClient) {
-	w.client = client
-}
+// Start 开始监听，直到 ctx 结束。变化按 Debounce 合并后同步调用 handler，handler 执行期间的变化会排在下一批
+func (w *Watcher) Start(ctx context.Context, handler Handler) error {
+	changes := make(chan string, 256)
+
+	if !w.opts.Poll {
+		err := w.startNotify(ctx, changes)
+		if err == nil {
+			return w.dispatch(ctx, changes, handler)
+		}
+		logger.Warnf("File system notifications unavailable (%v), falling back to polling every %s", err, w.opts.Interval)
+	}
 
-func (w *Watcher) Start(ctx context.Context) error {
-	if err := w.scanInitial(); err != nil {
+	if err := w.startPoll(ctx, changes); err != nil {
 		return err
 	}
+	return w.dispatch(ctx, changes, handler)
+}
 
-	ticker := time.NewTicker(w.interval)
-	defer ticker.Stop()
+// dispatch 收集变化的路径，Debounce 时间内没有新的变化时作为一批交给 handler
+func (w *Watcher) dispatch(ctx context.Context, changes <-chan string, handler Handler) error {
+	pending := make(map[string]bool)
+	var flush <-chan time.Time
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-ticker.C:
-			if err := w.checkChanges(ctx); err != nil {
-				logger.Warn("Error checking changes: %s", err.Error())
+		case rel := <-changes:
+			pending[rel] = true
+			flush = time.After(w.opts.Debounce)
+		case <-flush:
+			flush = nil
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
 			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			handler(ctx, paths)
 		}
 	}
 }
 
-func (w *Watcher) scanInitial() error {
-	files, err := file.WalkDirectory(
-		w.rootDir,
-		[]string{".json", ".js", ".xml", ".bpmn"},
-		[]string{".git", "node_modules", ".idea", ".vscode"},
-	)
+// startNotify 为应用目录及其未被忽略的子目录注册文件系统通知，新建的目录在事件到达时补充注册
+func (w *Watcher) startNotify(ctx context.Context, changes chan<- string) error {
+	fw, err := fsnotify.NewWatcher()
 	if err != nil {
 		return err
 	}
-
-	for _, relPath := range files {
-		fullPath := filepath.Join(w.rootDir, relPath)
-		hash, err := file.HashFile(fullPath)
-		if err != nil {
-			logger.Warn("Failed to hash file %s: %s", relPath, err.Error())
-			continue
-		}
-		w.lastHash[relPath] = hash
+	dirs, err := w.addTree(fw, w.rootDir)
+	if err != nil {
+		fw.Close()
+		return err
 	}
+	logger.Infof("Watching %d directories for changes", dirs)
 
-	logger.Infof("Watching %d files", len(files))
+	go func() {
+		defer fw.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-fw.Events:
+				if !ok {
+					return
+				}
+				w.onNotify(ctx, fw, event, changes)
+			case err, ok := <-fw.Errors:
+				if !ok {
+					return
+				}
+				if errors.Is(err, fsnotify.ErrEventOverflow) {
+					// 事件队列溢出后无法知道哪些文件变了，对整个应用重新比较
+					logger.Warn("Too many file events, rescanning the whole application")
+					send(ctx, changes, ".")
+					continue
+				}
+				logger.Warnf("File watcher error: %v", err)
+			}
+		}
+	}()
 	return nil
 }
 
-func (w *Watcher) checkChanges(ctx context.Context) error {
-	files, err := file.WalkDirectory(
-		w.rootDir,
-		[]string{".json", ".js", ".xml", ".bpmn"},
-		[]string{".git", "node_modules", ".idea", ".vscode"},
-	)
-	if err != nil {
-		return err
+func (w *Watcher) onNotify(ctx context.Context, fw *fsnotify.Watcher, event fsnotify.Event, changes chan<- string) {
+	if event.Op == fsnotify.Chmod {
+		return
 	}
+	rel, err := filepath.Rel(w.rootDir, event.Name)
+	if err != nil || rel == "." {
+		return
+	}
+	rel = filepath.ToSlash(rel)
 
-	currentHash := make(map[string]string)
-	for _, relPath := range files {
-		fullPath := filepath.Join(w.rootDir, relPath)
-		hash, err := file.HashFile(fullPath)
-		if err != nil {
-			logger.Warn("Failed to hash file %s: %s", relPath, err.Error())
-			continue
+	// 忽略规则变化后原先被忽略的文件可能需要同步，对整个应用重新比较
+	if rel == file.IgnoreFile {
+		if ignore, err := file.LoadIgnore(w.rootDir); err == nil {
+			w.ignore = ignore
+		} else {
+			logger.Warnf("%v", err)
 		}
-		currentHash[relPath] = hash
+		send(ctx, changes, ".")
+		return
 	}
 
-	for relPath, hash := range currentHash {
-		if _, exists := w.lastHash[relPath]; !exists {
-			w.onEvent(WatchEvent{Type: "created", Path: filepath.Join(w.rootDir, relPath), RelPath: relPath})
-		} else if w.lastHash[relPath] != hash {
-			w.onEvent(WatchEvent{Type: "modified", Path: filepath.Join(w.rootDir, relPath), RelPath: relPath})
+	isDir := false
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+			isDir = true
 		}
 	}
+	if w.ignore.Ignored(rel, isDir) {
+		return
+	}
 
-	for relPath := range w.lastHash {
-		if _, exists := currentHash[relPath]; !exists {
-			w.onEvent(WatchEvent{Type: "deleted", Path: relPath, RelPath: relPath})
+	// 新建目录时其中可能已经有文件，注册监听后整个目录作为变化
+	if isDir {
+		if _, err := w.addTree(fw, event.Name); err != nil {
+			logger.Warnf("Failed to watch %s: %v", rel, err)
 		}
 	}
-
-	w.lastHash = currentHash
-	return nil
+	logger.Debugf("[%s] %s", event.Op, rel)
+	send(ctx, changes, rel)
 }
 
-func (w *Watcher) onEvent(event WatchEvent) {
-	switch event.Type {
-	case "created":
-		logger.Success("[CREATED] %s", event.RelPath)
-	case "modified":
-		logger.Info("[MODIFIED] %s", event.RelPath)
-	case "deleted":
-		logger.Warn("[DELETED] %s", event.RelPath)
-	}
-
-	if w.client != nil {
-		w.syncEvent(event)
+func send(ctx context.Context, changes chan<- string, rel string) {
+	select {
+	case changes <- rel:
+	case <-ctx.Done():
 	}
 }
 
-func (w *Watcher) syncEvent(event WatchEvent) {
-	logger.Debug("Syncing event to cloud: %s", event.Type)
-
-	eventData := map[string]string{
-		"type":   event.Type,
-		"path":   event.RelPath,
-		"root":   w.rootDir,
-	}
+// addTree 注册 dir 及其下未被忽略的子目录，返回注册的目录数
+func (w *Watcher) addTree(fw *fsnotify.Watcher, dir string) (int, error) {
+	count := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// 遍历期间被删除的目录忽略即可
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(w.rootDir, path)
+		if rel != "." && w.ignore.Ignored(filepath.ToSlash(rel), true) {
+			return filepath.SkipDir
+		}
+		if err := fw.Add(path); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
 
-	_, err := w.client.Post("/api/v1/sync/event", eventData)
+// startPoll 每隔 Interval 按修改时间与大小比较一次文件，不读取文件内容
+func (w *Watcher) startPoll(ctx context.Context, changes chan<- string) error {
+	last, err := w.scan()
 	if err != nil {
-		logger.Warn("Failed to sync event: %s", err.Error())
+		return err
 	}
-}
+	logger.Infof("Polling %d files every %s", len(last), w.opts.Interval)
+
+	go func() {
+		ticker := time.NewTicker(w.opts.Interval)
+		defer ticker.Stop()
 
-func isIgnoredDir(dirName string) bool {
-	ignored := []string{"node_modules", ".git", "vendor", "__pycache__", ".idea", ".vscode"}
-	for _, ig := range ignored {
-		if dirName == ig {
-			return true
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := w.scan()
+			if err != nil {
+				logger.Warnf("Error checking changes: %v", err)
+				continue
+			}
+			for rel, stat := range current {
+				if prev, ok := last[rel]; !ok || prev != stat {
+					send(ctx, changes, rel)
+				}
+			}
+			for rel := range last {
+				if _, ok := current[rel]; !ok {
+					send(ctx, changes, rel)
+				}
+			}
+			last = current
 		}
-	}
-	return false
+	}()
+	return nil
 }
 
-func isIgnoredFile(fileName string) bool {
-	ignoredPrefixes := []string{".", "_"}
-	for _, prefix := range ignoredPrefixes {
-		if strings.HasPrefix(fileName, prefix) {
-			return true
-		}
+// scan 列出未被忽略的文件，每次扫描重新读取 .geelatoignore 以便修改后立即生效
+func (w *Watcher) scan() (map[string]fileStat, error) {
+	ignore, err := file.LoadIgnore(w.rootDir)
+	if err != nil {
+		return nil, err
 	}
-	return false
+	w.ignore = ignore
+
+	files := make(map[string]fileStat)
+	err = filepath.Walk(w.rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, _ := filepath.Rel(w.rootDir, path)
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if ignore.Ignored(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			files[rel] = fileStat{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return files, err
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/geelato/cli/cmd"
+	"github.com/geelato/cli/pkg/errors"
 	"github.com/geelato/cli/pkg/logger"
 )
 
@@ -17,6 +18,6 @@ func main() {
 	logger.Infof("Geelato CLI version %s", version)
 	if err := cmd.Execute(); err != nil {
 		logger.Errorf("执行失败: %v", err)
-		os.Exit(1)
+		os.Exit(errors.ExitCode(err))
 	}
 }
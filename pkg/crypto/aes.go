@@ -0,0 +1,118 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// SaltSize 口令派生密钥使用的随机盐长度
+	SaltSize = 16
+
+	// scrypt 参数，派生一次约占用 32MB 内存
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// passphraseMagic 口令加密数据的格式标识，其后依次为盐、nonce 与密文
+var passphraseMagic = []byte("GLC1")
+
+// DeriveKey 使用 scrypt 由口令和盐派生 AES-256 密钥
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// EncryptWithPassphrase 每次使用新的随机盐派生密钥并加密，盐与密文保存在一起
+func EncryptWithPassphrase(passphrase string, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("生成随机盐失败: %w", err)
+	}
+
+	key, err := DeriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := EncryptAESGCM(key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, len(passphraseMagic)+SaltSize+len(sealed))
+	data = append(data, passphraseMagic...)
+	data = append(data, salt...)
+	return append(data, sealed...), nil
+}
+
+// DecryptWithPassphrase 解密 EncryptWithPassphrase 生成的数据。
+// 没有格式标识的数据是旧版本以口令 sha256 为密钥加密的，仍可读取，下次写入时改用新格式
+func DecryptWithPassphrase(passphrase string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, passphraseMagic) {
+		sum := sha256.Sum256([]byte(passphrase))
+		return DecryptAESGCM(sum[:], data)
+	}
+
+	data = data[len(passphraseMagic):]
+	if len(data) < SaltSize {
+		return nil, fmt.Errorf("密文长度无效")
+	}
+	key, err := DeriveKey(passphrase, data[:SaltSize])
+	if err != nil {
+		return nil, err
+	}
+	return DecryptAESGCM(key, data[SaltSize:])
+}
+
+// EncryptAESGCM 使用 AES-GCM 加密，返回 nonce 与密文拼接后的数据
+func EncryptAESGCM(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// DecryptAESGCM 解密 EncryptAESGCM 生成的数据
+func DecryptAESGCM(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("密文长度无效")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("解密失败: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -58,6 +58,10 @@ const (
 	ErrSyncFailed Code = 2005002
 	ErrSyncVersion Code = 2005003
 
+	ErrHook Code = 2006000
+	ErrHookInvalid Code = 2006001
+	ErrHookFailed Code = 2006002
+
 	ErrPlatform Code = 3001000
 	ErrPlatformAuth Code = 3001001
 	ErrPlatformRequest Code = 3001002
@@ -120,6 +124,10 @@ var messages = map[Code]string{
 	ErrSyncFailed: "同步失败",
 	ErrSyncVersion: "版本不一致",
 
+	ErrHook: "钩子错误",
+	ErrHookInvalid: "钩子配置无效",
+	ErrHookFailed: "钩子执行失败",
+
 	ErrPlatform: "平台错误",
 	ErrPlatformAuth: "平台认证失败",
 	ErrPlatformRequest: "平台请求失败",
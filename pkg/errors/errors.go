@@ -72,6 +72,26 @@ func Is(err error, code Code) bool {
 	return false
 }
 
+// exitCodes 需要被脚本和 CI 区分的错误对应的进程退出码，其余错误退出码为 1
+var exitCodes = map[Code]int{
+	ErrSyncConflict: 3,
+	ErrHookFailed:   4,
+}
+
+// ExitCode 返回错误对应的进程退出码
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ge *GeelatoError
+	if errors.As(err, &ge) {
+		if code, ok := exitCodes[ge.Code]; ok {
+			return code
+		}
+	}
+	return 1
+}
+
 func Equal(err error, target error) bool {
 	return errors.Is(err, target)
 }
@@ -0,0 +1,160 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/geelato/cli/pkg/logger"
+)
+
+// Policy 描述失败重试的策略：指数退避、随机抖动与最大尝试次数
+type Policy struct {
+	MaxAttempts     int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Jitter 随机抖动比例，取值 0~1
+	Jitter float64
+}
+
+// DefaultPolicy 返回默认重试策略
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:     3,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+	}
+}
+
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable 标记错误可以重试，after 大于 0 时表示服务端要求的最短等待时间
+func Retryable(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err, after: after}
+}
+
+// Do 执行 fn，遇到 Retryable 标记的错误时按策略等待后重试。
+// 返回的错误会去掉 Retryable 标记。
+func (p Policy) Do(ctx context.Context, fn func(attempt int) error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+
+		var re *retryableError
+		if !errors.As(err, &re) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return re.err
+		}
+
+		wait := p.Backoff(attempt)
+		if re.after > wait {
+			wait = re.after
+		}
+
+		logger.Debugf("请求失败，%v 后重试 (%d/%d): %v", wait, attempt+1, maxAttempts, re.err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return re.err
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff 返回第 attempt 次失败后的等待时间
+func (p Policy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	wait := float64(p.InitialInterval) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxInterval > 0 && wait > float64(p.MaxInterval) {
+		wait = float64(p.MaxInterval)
+	}
+
+	if p.Jitter > 0 {
+		delta := wait * p.Jitter
+		wait = wait - delta + rand.Float64()*2*delta
+	}
+
+	return time.Duration(wait)
+}
+
+// RetryableStatus 判断 HTTP 状态码是否值得重试
+func RetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseRetryAfter 解析 Retry-After 响应头，支持秒数与 HTTP 日期两种格式
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// IdempotentMethod 判断 HTTP 方法是否天然幂等
+func IdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}